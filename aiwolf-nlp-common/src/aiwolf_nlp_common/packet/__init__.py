@@ -1,3 +1,4 @@
+from .hello import Hello
 from .info import Info
 from .judge import Judge
 from .packet import Packet
@@ -9,6 +10,7 @@ from .talk import Talk
 from .vote import Vote
 
 __all__ = [
+    "Hello",
     "Info",
     "Judge",
     "Packet",
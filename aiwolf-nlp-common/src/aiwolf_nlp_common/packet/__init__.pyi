@@ -2,6 +2,7 @@
 This type stub file was generated by pyright.
 """
 
+from .hello import Hello
 from .info import Info
 from .judge import Judge
 from .packet import Packet
@@ -15,4 +16,4 @@ from .vote import Vote
 """
 This type stub file was generated by pyright.
 """
-__all__ = ["Info", "Judge", "Packet", "Request", "Role", "Setting", "Species", "Status", "Talk", "Team", "Vote"]
+__all__ = ["Hello", "Info", "Judge", "Packet", "Request", "Role", "Setting", "Species", "Status", "Talk", "Team", "Vote"]
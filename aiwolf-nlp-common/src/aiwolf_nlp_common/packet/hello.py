@@ -0,0 +1,32 @@
+# ruff: noqa: D102, ANN401
+
+from dataclasses import dataclass, field
+from typing import Any
+
+
+@dataclass
+class Hello:
+    """自己紹介の結果を示す情報の構造体.
+
+    Attributes:
+        protocol (int): サーバのプロトコルのバージョン.
+        name (str): エージェントの名前.
+        capabilities (list[str]): エージェントとサーバの双方が対応している機能.
+        language (str | None): エージェントの言語.
+        team (str | None): チーム名.
+    """
+
+    protocol: int
+    name: str
+    capabilities: list[str] = field(default_factory=list)
+    language: str | None = None
+    team: str | None = None
+
+    @staticmethod
+    def from_dict(obj: Any) -> "Hello":
+        _protocol = int(obj.get("protocol"))
+        _name = str(obj.get("name"))
+        _capabilities = [str(y) for y in obj.get("capabilities")] if obj.get("capabilities") is not None else []
+        _language = str(obj.get("language")) if obj.get("language") is not None else None
+        _team = str(obj.get("team")) if obj.get("team") is not None else None
+        return Hello(_protocol, _name, _capabilities, _language, _team)
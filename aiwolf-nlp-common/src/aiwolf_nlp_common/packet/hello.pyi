@@ -0,0 +1,32 @@
+"""
+This type stub file was generated by pyright.
+"""
+
+from dataclasses import dataclass
+from typing import Any
+
+"""
+This type stub file was generated by pyright.
+"""
+@dataclass
+class Hello:
+    """自己紹介の結果を示す情報の構造体.
+
+    Attributes:
+        protocol (int): サーバのプロトコルのバージョン.
+        name (str): エージェントの名前.
+        capabilities (list[str]): エージェントとサーバの双方が対応している機能.
+        language (str | None): エージェントの言語.
+        team (str | None): チーム名.
+    """
+    protocol: int
+    name: str
+    capabilities: list[str] = ...
+    language: str | None = ...
+    team: str | None = ...
+    @staticmethod
+    def from_dict(obj: Any) -> Hello:
+        ...
+    
+
+
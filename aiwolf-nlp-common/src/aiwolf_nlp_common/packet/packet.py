@@ -5,6 +5,7 @@ from __future__ import annotations
 from dataclasses import dataclass
 from typing import Any
 
+from aiwolf_nlp_common.packet.hello import Hello
 from aiwolf_nlp_common.packet.info import Info
 from aiwolf_nlp_common.packet.request import Request
 from aiwolf_nlp_common.packet.setting import Setting
@@ -21,6 +22,14 @@ class Packet:
         setting (Setting | None): ゲームの設定情報.
         talk_history (list[Talk] | None): トークの履歴を示す情報.
         whisper_history (list[Talk] | None): 囁きの履歴を示す情報.
+        mason_talk_history (list[Talk] | None): 共有会話の履歴を示す情報.
+        graveyard_talk_history (list[Talk] | None): 墓場チャットの履歴を示す情報.
+        bid_request (Request | None): 入札の対象となるリクエストの種類.
+        prompt_reason (str | None): 発言催促の理由.
+        deadline (str | None): リアルタイム通信のフェーズの締め切り.
+        remaining_time (int | None): フェーズの締め切りまでの残り時間 (ミリ秒).
+        request_id (str | None): リクエストを識別するID.
+        hello (Hello | None): 自己紹介の結果.
     """
 
     request: Request
@@ -28,6 +37,14 @@ class Packet:
     setting: Setting | None
     talk_history: list[Talk] | None
     whisper_history: list[Talk] | None
+    mason_talk_history: list[Talk] | None = None
+    graveyard_talk_history: list[Talk] | None = None
+    bid_request: Request | None = None
+    prompt_reason: str | None = None
+    deadline: str | None = None
+    remaining_time: int | None = None
+    request_id: str | None = None
+    hello: Hello | None = None
 
     @staticmethod
     def from_dict(obj: Any) -> Packet:
@@ -40,4 +57,34 @@ class Packet:
         _whisper_history = (
             [Talk.from_dict(y) for y in obj.get("whisper_history")] if obj.get("whisper_history") is not None else None
         )
-        return Packet(_request, _info, _setting, _talk_history, _whisper_history)
+        _mason_talk_history = (
+            [Talk.from_dict(y) for y in obj.get("mason_talk_history")]
+            if obj.get("mason_talk_history") is not None
+            else None
+        )
+        _graveyard_talk_history = (
+            [Talk.from_dict(y) for y in obj.get("graveyard_talk_history")]
+            if obj.get("graveyard_talk_history") is not None
+            else None
+        )
+        _bid_request = Request(str(obj.get("bid_request"))) if obj.get("bid_request") is not None else None
+        _prompt_reason = str(obj.get("prompt_reason")) if obj.get("prompt_reason") is not None else None
+        _deadline = str(obj.get("deadline")) if obj.get("deadline") is not None else None
+        _remaining_time = int(obj.get("remaining_time")) if obj.get("remaining_time") is not None else None
+        _request_id = str(obj.get("request_id")) if obj.get("request_id") is not None else None
+        _hello = Hello.from_dict(obj.get("hello")) if obj.get("hello") is not None else None
+        return Packet(
+            _request,
+            _info,
+            _setting,
+            _talk_history,
+            _whisper_history,
+            _mason_talk_history,
+            _graveyard_talk_history,
+            _bid_request,
+            _prompt_reason,
+            _deadline,
+            _remaining_time,
+            _request_id,
+            _hello,
+        )
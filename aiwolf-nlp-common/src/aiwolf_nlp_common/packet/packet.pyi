@@ -4,6 +4,7 @@ This type stub file was generated by pyright.
 
 from dataclasses import dataclass
 from typing import Any
+from aiwolf_nlp_common.packet.hello import Hello
 from aiwolf_nlp_common.packet.info import Info
 from aiwolf_nlp_common.packet.request import Request
 from aiwolf_nlp_common.packet.setting import Setting
@@ -22,12 +23,28 @@ class Packet:
         setting (Setting | None): ゲームの設定情報.
         talk_history (list[Talk] | None): トークの履歴を示す情報.
         whisper_history (list[Talk] | None): 囁きの履歴を示す情報.
+        mason_talk_history (list[Talk] | None): 共有会話の履歴を示す情報.
+        graveyard_talk_history (list[Talk] | None): 墓場チャットの履歴を示す情報.
+        bid_request (Request | None): 入札の対象となるリクエストの種類.
+        prompt_reason (str | None): 発言催促の理由.
+        deadline (str | None): リアルタイム通信のフェーズの締め切り.
+        remaining_time (int | None): フェーズの締め切りまでの残り時間 (ミリ秒).
+        request_id (str | None): リクエストを識別するID.
+        hello (Hello | None): 自己紹介の結果.
     """
     request: Request
     info: Info | None
     setting: Setting | None
     talk_history: list[Talk] | None
     whisper_history: list[Talk] | None
+    mason_talk_history: list[Talk] | None = None
+    graveyard_talk_history: list[Talk] | None = None
+    bid_request: Request | None = None
+    prompt_reason: str | None = None
+    deadline: str | None = None
+    remaining_time: int | None = None
+    request_id: str | None = None
+    hello: Hello | None = None
     @staticmethod
     def from_dict(obj: Any) -> Packet:
         ...
@@ -28,6 +28,16 @@ class Request(str, Enum):
         WHISPER_START (str): リアルタイム囁き開始リクエスト.
         WHISPER_BROADCAST (str): リアルタイム囁きブロードキャスト.
         WHISPER_END (str): リアルタイム囁き終了リクエスト.
+        MASON_TALK (str): 共有会話リクエスト.
+        MASON_TALK_START (str): リアルタイム共有会話開始リクエスト.
+        MASON_TALK_BROADCAST (str): リアルタイム共有会話ブロードキャスト.
+        MASON_TALK_END (str): リアルタイム共有会話終了リクエスト.
+        LAST_WORDS (str): 遺言リクエスト.
+        GRAVEYARD_TALK (str): 墓場チャットリクエスト.
+        BID (str): 発言権の入札リクエスト.
+        TALK_PROMPT (str): リアルタイム発言催促リクエスト.
+        TALK_REMAINING_TIME (str): リアルタイム残り時間通知.
+        HELLO (str): 自己紹介の結果.
     """
     NAME = ...
     TALK = ...
@@ -46,5 +56,15 @@ class Request(str, Enum):
     WHISPER_START = ...
     WHISPER_BROADCAST = ...
     WHISPER_END = ...
+    MASON_TALK = ...
+    MASON_TALK_START = ...
+    MASON_TALK_BROADCAST = ...
+    MASON_TALK_END = ...
+    LAST_WORDS = ...
+    GRAVEYARD_TALK = ...
+    BID = ...
+    TALK_PROMPT = ...
+    TALK_REMAINING_TIME = ...
+    HELLO = ...
 
 
@@ -9,10 +9,12 @@ class Team(str, Enum):
     Attributes:
         VILLAGER (str): 市民陣営.
         WEREWOLF (str): 人狼陣営.
+        FOX (str): 妖狐陣営.
     """
 
     VILLAGER = "VILLAGER"
     WEREWOLF = "WEREWOLF"
+    FOX = "FOX"
 
 
 class Species(str, Enum):
@@ -37,6 +39,11 @@ class Role(str, Enum):
         BODYGUARD (str): 騎士.
         VILLAGER (str): 村人.
         MEDIUM (str): 霊媒師.
+        FOX (str): 妖狐.
+        MASON (str): 共有者.
+
+    サーバの設定で独自に定義された役職 (例: FANATIC) は、列挙型にない役職として生成されます.
+    独自に定義された役職の陣営と種族は、市民陣営と人間として扱います.
     """
 
     WEREWOLF = "WEREWOLF"
@@ -45,11 +52,24 @@ class Role(str, Enum):
     BODYGUARD = "BODYGUARD"
     VILLAGER = "VILLAGER"
     MEDIUM = "MEDIUM"
+    FOX = "FOX"
+    MASON = "MASON"
+
+    @classmethod
+    def _missing_(cls, value: object) -> "Role | None":
+        if not isinstance(value, str) or not value:
+            return None
+        member = str.__new__(cls, value)
+        member._name_ = value
+        member._value_ = value
+        return cls._value2member_map_.setdefault(value, member)
 
     @property
     def team(self) -> Team:
         if self in [Role.WEREWOLF, Role.POSSESSED]:
             return Team.WEREWOLF
+        if self == Role.FOX:
+            return Team.FOX
         return Team.VILLAGER
 
     @property
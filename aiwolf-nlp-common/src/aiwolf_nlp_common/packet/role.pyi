@@ -13,9 +13,12 @@ class Team(str, Enum):
     Attributes:
         VILLAGER (str): 市民陣営.
         WEREWOLF (str): 人狼陣営.
+        FOX (str): 妖狐陣営.
     """
     VILLAGER = ...
     WEREWOLF = ...
+    FOX = ...
+    MASON = ...
 
 
 class Species(str, Enum):
@@ -39,6 +42,11 @@ class Role(str, Enum):
         BODYGUARD (str): 騎士.
         VILLAGER (str): 村人.
         MEDIUM (str): 霊媒師.
+        FOX (str): 妖狐.
+        MASON (str): 共有者.
+
+    サーバの設定で独自に定義された役職 (例: FANATIC) は、列挙型にない役職として生成されます.
+    独自に定義された役職の陣営と種族は、市民陣営と人間として扱います.
     """
     WEREWOLF = ...
     POSSESSED = ...
@@ -46,6 +54,12 @@ class Role(str, Enum):
     BODYGUARD = ...
     VILLAGER = ...
     MEDIUM = ...
+    FOX = ...
+    MASON = ...
+    @classmethod
+    def _missing_(cls, value: object) -> Role | None:
+        ...
+    
     @property
     def team(self) -> Team:
         ...
@@ -16,6 +16,8 @@ class Talk:
         text (str): 会話の内容.
         skip (bool): 会話がスキップであるかどうか.
         over (bool): 会話がオーバーであるかどうか.
+        last_words (bool): 会話が遺言であるかどうか.
+        position (int): ターン内での発言の位置.
     """
 
     idx: int
@@ -25,6 +27,8 @@ class Talk:
     text: str
     skip: bool = False
     over: bool = False
+    last_words: bool = False
+    position: int = 0
 
     @staticmethod
     def from_dict(obj: Any) -> "Talk":
@@ -35,4 +39,6 @@ class Talk:
         _text = str(obj.get("text"))
         _skip = bool(obj.get("skip"))
         _over = bool(obj.get("over"))
-        return Talk(_idx, _day, _turn, _agent, _text, _skip, _over)
+        _last_words = bool(obj.get("last_words"))
+        _position = int(obj.get("position", 0))
+        return Talk(_idx, _day, _turn, _agent, _text, _skip, _over, _last_words, _position)
@@ -20,6 +20,8 @@ class Talk:
         text (str): 会話の内容.
         skip (bool): 会話がスキップであるかどうか.
         over (bool): 会話がオーバーであるかどうか.
+        last_words (bool): 会話が遺言であるかどうか.
+        position (int): ターン内での発言の位置.
     """
     idx: int
     day: int
@@ -28,6 +30,8 @@ class Talk:
     text: str
     skip: bool = ...
     over: bool = ...
+    last_words: bool = ...
+    position: int = ...
     @staticmethod
     def from_dict(obj: Any) -> Talk:
         ...
@@ -15,11 +15,15 @@ class Vote:
     Attributes:
         day (int): 投票が行われた日数.
         agent (str): 投票を行ったエージェントの名前.
-        target (str): 投票の対象となったエージェントの名前.
+        target (str | None): 投票の対象となったエージェントの名前. 棄権の場合は None.
+        round (int): 同じ日の中での投票の回数. 初回の投票は 0.
+        abstain (bool): 棄権であるか.
     """
     day: int
     agent: str
-    target: str
+    target: str | None
+    round: int = 0
+    abstain: bool = False
     @staticmethod
     def from_dict(obj: Any) -> Vote:
         ...
@@ -45,3 +45,15 @@ def test_info() -> None:
     assert info.remain_count is None
     assert info.remain_length is None
     assert info.remain_skip is None
+
+
+def test_info_custom_role() -> None:
+    value = json.loads(
+        """{"game_id":"01JQRBM0SBFWKQVMC8EFARBDKW","day":0,"agent":"Agent[01]","status_map":{"Agent[01]":"ALIVE","Agent[02]":"ALIVE"},"role_map":{"Agent[01]":"FANATIC"}}""",
+    )
+    info = Info.from_dict(value)
+
+    assert info.role_map["Agent[01]"] == "FANATIC"
+    assert info.role_map["Agent[01]"] is Role("FANATIC")
+    assert info.role_map["Agent[01]"].name == "FANATIC"
+    assert info.role_map["Agent[01]"] not in list(Role)
@@ -69,7 +69,15 @@ func Analyzer(config model.Config) {
 			slog.Warn("ファイルの取得に失敗しました", "error", err)
 		}
 
+		// 独自に定義された役職の陣営で勝敗を判定するため、役職の定義から役職を取得する
+		definitions, err := model.RoleDefinitionsFromConfig(config)
+		if err != nil {
+			slog.Warn("役職の定義の読み込みに失敗したため、既定の役職の定義を使用します", "error", err)
+			definitions = model.DefaultRoleDefinitions()
+		}
+
 		counts := make(map[string]map[model.Role]*Count)
+		reasons := make(map[model.ResultReason]int)
 
 		for _, filePath := range filePaths {
 			file, err := os.Open(filePath)
@@ -81,6 +89,7 @@ func Analyzer(config model.Config) {
 			teamsRole := make(map[string]model.Role)
 			errorTeams := []string{}
 			var winSide *model.Team
+			var reason model.ResultReason
 
 			scanner := bufio.NewScanner(file)
 			for scanner.Scan() {
@@ -89,7 +98,7 @@ func Analyzer(config model.Config) {
 				if (len(values) == 6 || len(values) == 7) && values[1] == "status" {
 					if values[0] == "0" {
 						team := strings.TrimRight(values[5], "1234567890")
-						role := model.RoleFromString(values[3])
+						role := definitions.Role(values[3])
 						teamsRole[team] = role
 					} else {
 						team := strings.TrimRight(values[5], "1234567890")
@@ -99,9 +108,13 @@ func Analyzer(config model.Config) {
 						}
 					}
 				}
-				if len(values) == 5 && values[1] == "result" {
+				if len(values) >= 5 && values[1] == "result" {
 					side := model.TeamFromString(values[4])
 					winSide = &side
+					// 終了理由を記録していない古いログの場合は、空の終了理由として扱う
+					if len(values) >= 6 {
+						reason = model.ResultReason(values[5])
+					}
 				}
 			}
 
@@ -115,6 +128,8 @@ func Analyzer(config model.Config) {
 				continue
 			}
 
+			reasons[reason]++
+
 			for team, role := range teamsRole {
 				if _, exists := counts[team]; !exists {
 					counts[team] = make(map[model.Role]*Count)
@@ -127,14 +142,15 @@ func Analyzer(config model.Config) {
 					counts[team][role].Error++
 				}
 
-				if *winSide == model.T_NONE {
+				// 最大日数に到達して勝利陣営が決まらなかった場合は、引き分けとして数える
+				if *winSide == model.T_NONE && reason == model.RR_MAX_DAY {
+					counts[team][role].Draw++
+				} else if *winSide == model.T_NONE {
 					counts[team][role].None++
 				} else {
 					counts[team][role].Succeed++
 
-					if *winSide == model.T_VILLAGER && role != model.R_WEREWOLF && role != model.R_POSSESSED {
-						counts[team][role].Win++
-					} else if *winSide == model.T_WEREWOLF && (role == model.R_WEREWOLF || role == model.R_POSSESSED) {
+					if role.Team == *winSide {
 						counts[team][role].Win++
 					} else {
 						counts[team][role].Lose++
@@ -146,15 +162,17 @@ func Analyzer(config model.Config) {
 		for team, roles := range counts {
 			global := &Count{}
 			for role, count := range roles {
-				slog.Info("統計データを取得しました", "team", team, "role", role, "win", count.Win, "lose", count.Lose, "error", count.Error, "none", count.None, "succeed", count.Succeed)
+				slog.Info("統計データを取得しました", "team", team, "role", role, "win", count.Win, "lose", count.Lose, "error", count.Error, "none", count.None, "draw", count.Draw, "succeed", count.Succeed)
 				global.Win += count.Win
 				global.Lose += count.Lose
 				global.Error += count.Error
 				global.None += count.None
+				global.Draw += count.Draw
 				global.Succeed += count.Succeed
 			}
-			slog.Info("統計データを取得しました", "team", team, "win", global.Win, "lose", global.Lose, "error", global.Error, "none", global.None, "succeed", global.Succeed)
+			slog.Info("統計データを取得しました", "team", team, "win", global.Win, "lose", global.Lose, "error", global.Error, "none", global.None, "draw", global.Draw, "succeed", global.Succeed)
 		}
+		slog.Info("終了理由ごとの試合数を取得しました", "reasons", reasons)
 	}
 }
 
@@ -199,6 +217,7 @@ func Reduction(src model.Config, dst model.Config) {
 type Count struct {
 	Succeed int
 	None    int
+	Draw    int
 	Win     int
 	Lose    int
 	Error   int
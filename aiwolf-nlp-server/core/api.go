@@ -2,9 +2,11 @@ package core
 
 import (
 	"net/http"
+	"slices"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/logic"
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,6 +25,8 @@ type CostReport struct {
 	OutputCost   float64 `json:"output_cost"`
 	TotalCost    float64 `json:"total_cost"`
 	CallCount    int     `json:"call_count"`
+	// fromUsage は構造化されたレスポンスから集計したトークン使用量によるコストレポートであるかどうかです
+	fromUsage bool
 }
 
 // GameCostSummary はゲームごとのコスト集計です
@@ -127,22 +131,7 @@ func (s *Server) handleStatus(c *gin.Context) {
 	}
 
 	// コスト集計
-	var costs []GameCostSummary
-	s.costReports.Range(func(key, value any) bool {
-		gameID := key.(string)
-		reports := value.([]CostReport)
-		summary := GameCostSummary{
-			GameID: gameID,
-			Agents: reports,
-		}
-		for _, r := range reports {
-			summary.TotalCost += r.TotalCost
-			summary.TotalInput += r.InputTokens
-			summary.TotalOutput += r.OutputTokens
-		}
-		costs = append(costs, summary)
-		return true
-	})
+	costs := s.collectCostSummaries()
 	if costs == nil {
 		resp.Costs = []GameCostSummary{}
 	} else {
@@ -200,6 +189,20 @@ func (s *Server) handleStatus(c *gin.Context) {
 
 // handleGameStart は待機部屋のエージェントでゲームを開始します
 func (s *Server) handleGameStart(c *gin.Context) {
+	var req struct {
+		Seed *int64 `json:"seed"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "不正なリクエスト"})
+			return
+		}
+	}
+	if req.Seed == nil {
+		req.Seed = s.config.Game.Seed
+	}
+	seed := util.ResolveSeed(req.Seed)
+
 	var game *logic.Game
 
 	if s.config.Matching.IsOptimize {
@@ -214,14 +217,14 @@ func (s *Server) handleGameStart(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		game = logic.NewGameWithRole(&s.config, s.gameSetting, roleMapConns)
+		game = logic.NewGameWithRole(&s.config, s.gameSetting, roleMapConns, seed)
 	} else {
-		connections, err := s.waitingRoom.GetConnections()
+		connections, err := s.waitingRoom.GetConnections(util.NewRand(seed, util.STREAM_MATCHING))
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		game = logic.NewGame(&s.config, s.gameSetting, connections)
+		game = logic.NewGame(&s.config, s.gameSetting, connections, seed)
 	}
 
 	if s.jsonLogger != nil {
@@ -240,6 +243,7 @@ func (s *Server) handleGameStart(c *gin.Context) {
 
 	go func() {
 		winSide := game.Start()
+		s.storeUsageReports(game)
 		if s.config.Matching.IsOptimize {
 			if winSide != model.T_NONE {
 				s.matchOptimizer.setMatchEnd(game.GetRoleTeamNamesMap())
@@ -251,6 +255,7 @@ func (s *Server) handleGameStart(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"id":      game.GetID(),
+		"seed":    seed,
 		"message": "ゲームを開始しました",
 	})
 }
@@ -293,6 +298,47 @@ func (s *Server) handleGameResume(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "ゲームを再開しました", "id": id})
 }
 
+// collectCostSummaries はエージェントから送信されたコストレポートと、構造化されたレスポンスから集計したトークン使用量をゲームごとにまとめます
+// 同じエージェントのコストレポートが送信されている場合は、コストを含むコストレポートを優先します
+func (s *Server) collectCostSummaries() []GameCostSummary {
+	reportsMap := make(map[string][]CostReport)
+	s.costReports.Range(func(key, value any) bool {
+		reportsMap[key.(string)] = value.([]CostReport)
+		return true
+	})
+	s.games.Range(func(key, value any) bool {
+		game, ok := value.(*logic.Game)
+		if !ok {
+			return true
+		}
+		gameID := game.GetID()
+		for _, usage := range game.GetAgentUsages() {
+			if slices.ContainsFunc(reportsMap[gameID], func(r CostReport) bool {
+				return r.Agent == usage.Agent
+			}) {
+				continue
+			}
+			reportsMap[gameID] = append(reportsMap[gameID], newUsageReport(gameID, usage))
+		}
+		return true
+	})
+
+	var costs []GameCostSummary
+	for gameID, reports := range reportsMap {
+		summary := GameCostSummary{
+			GameID: gameID,
+			Agents: reports,
+		}
+		for _, r := range reports {
+			summary.TotalCost += r.TotalCost
+			summary.TotalInput += r.InputTokens
+			summary.TotalOutput += r.OutputTokens
+		}
+		costs = append(costs, summary)
+	}
+	return costs
+}
+
 // handleCostReport はエージェントからのコストレポートを受信します
 func (s *Server) handleCostReport(c *gin.Context) {
 	var report CostReport
@@ -306,17 +352,58 @@ func (s *Server) handleCostReport(c *gin.Context) {
 	}
 
 	// ゲームIDごとにレポートを蓄積
-	for {
-		value, loaded := s.costReports.LoadOrStore(report.GameID, []CostReport{report})
-		if !loaded {
-			break
-		}
-		existing := value.([]CostReport)
-		updated := append(existing, report)
-		if s.costReports.CompareAndSwap(report.GameID, value, updated) {
-			break
+	// 同じエージェントのトークン使用量から集計したコストレポートは、送信されたコストレポートで置き換える
+	s.updateCostReports(report.GameID, func(reports []CostReport) []CostReport {
+		reports = slices.DeleteFunc(reports, func(r CostReport) bool {
+			return r.fromUsage && r.Agent == report.Agent
+		})
+		return append(reports, report)
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "コストレポートを受信しました"})
+}
+
+// storeUsageReports はゲーム終了時に、構造化されたレスポンスから集計したトークン使用量をコストレポートとして保存します
+// 保存したコストレポートは、ゲームが保持されなくなった後も集計に含まれます
+func (s *Server) storeUsageReports(game *logic.Game) {
+	gameID := game.GetID()
+	usages := game.GetAgentUsages()
+	if len(usages) == 0 {
+		return
+	}
+	s.updateCostReports(gameID, func(reports []CostReport) []CostReport {
+		for _, usage := range usages {
+			if slices.ContainsFunc(reports, func(r CostReport) bool {
+				return r.Agent == usage.Agent
+			}) {
+				continue
+			}
+			reports = append(reports, newUsageReport(gameID, usage))
 		}
+		return reports
+	})
+}
+
+// updateCostReports はゲームIDごとのコストレポートを update の結果で置き換えます
+func (s *Server) updateCostReports(gameID string, update func(reports []CostReport) []CostReport) {
+	s.costMu.Lock()
+	defer s.costMu.Unlock()
+	var reports []CostReport
+	if value, exists := s.costReports.Load(gameID); exists {
+		reports = slices.Clone(value.([]CostReport))
 	}
+	s.costReports.Store(gameID, update(reports))
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "コストレポートを受信しました"})
+func newUsageReport(gameID string, usage logic.AgentUsage) CostReport {
+	return CostReport{
+		GameID:       gameID,
+		Agent:        usage.Agent,
+		Team:         usage.Team,
+		Model:        usage.Model,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		CallCount:    usage.CallCount,
+		fromUsage:    true,
+	}
 }
@@ -72,13 +72,13 @@ func (mo *MatchOptimizer) UnmarshalJSON(data []byte) error {
 	}
 	mo.RoleNumMap = make(map[model.Role]int)
 	for role, num := range aux.RoleNumMap {
-		mo.RoleNumMap[model.RoleFromString(role)] = num
+		mo.RoleNumMap[roleFromName(role)] = num
 	}
 	mo.EndedMatches = make([]map[model.Role][]int, len(aux.EndedMatches))
 	for i, match := range aux.EndedMatches {
 		mo.EndedMatches[i] = make(map[model.Role][]int)
 		for role, idxs := range match {
-			mo.EndedMatches[i][model.RoleFromString(role)] = idxs
+			mo.EndedMatches[i][roleFromName(role)] = idxs
 		}
 	}
 	mo.ScheduledMatches = make([]model.MatchWeight, len(aux.ScheduledMatches))
@@ -88,12 +88,48 @@ func (mo *MatchOptimizer) UnmarshalJSON(data []byte) error {
 			Weight:   scheduledMatch.Weight,
 		}
 		for role, idxs := range scheduledMatch.RoleIdxs {
-			mo.ScheduledMatches[i].RoleIdxs[model.RoleFromString(role)] = idxs
+			mo.ScheduledMatches[i].RoleIdxs[roleFromName(role)] = idxs
 		}
 	}
 	return nil
 }
 
+func roleFromName(name string) model.Role {
+	role := model.RoleFromString(name)
+	if role == model.R_NONE {
+		return model.Role{Name: name}
+	}
+	return role
+}
+
+func (mo *MatchOptimizer) resolveRoles(definitions model.RoleDefinitions) {
+	resolve := func(role model.Role) model.Role {
+		if resolved := definitions.Role(role.Name); resolved != model.R_NONE {
+			return resolved
+		}
+		return role
+	}
+	roleNumMap := make(map[model.Role]int)
+	for role, num := range mo.RoleNumMap {
+		roleNumMap[resolve(role)] = num
+	}
+	mo.RoleNumMap = roleNumMap
+	for i, match := range mo.EndedMatches {
+		resolved := make(map[model.Role][]int)
+		for role, idxs := range match {
+			resolved[resolve(role)] = idxs
+		}
+		mo.EndedMatches[i] = resolved
+	}
+	for i, scheduledMatch := range mo.ScheduledMatches {
+		resolved := make(map[model.Role][]int)
+		for role, idxs := range scheduledMatch.RoleIdxs {
+			resolved[resolve(role)] = idxs
+		}
+		mo.ScheduledMatches[i].RoleIdxs = resolved
+	}
+}
+
 func NewMatchOptimizer(config model.Config) (*MatchOptimizer, error) {
 	data, err := os.ReadFile(config.Matching.OutputPath)
 	if err != nil {
@@ -105,6 +141,11 @@ func NewMatchOptimizer(config model.Config) (*MatchOptimizer, error) {
 		slog.Error("マッチオプティマイザのパースに失敗しました", "error", err)
 		return nil, err
 	}
+	definitions, err := model.RoleDefinitionsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	mo.resolveRoles(definitions)
 	mo.outputPath = config.Matching.OutputPath
 	mo.save()
 	return &mo, nil
@@ -35,6 +35,7 @@ type Server struct {
 	realtimeBroadcaster *service.RealtimeBroadcaster
 	ttsBroadcaster      *service.TTSBroadcaster
 	costReports         sync.Map
+	costMu              sync.Mutex
 	spawnedProcesses    sync.Map
 }
 
@@ -56,6 +57,9 @@ func NewServer(config model.Config) (*Server, error) {
 		return nil, errors.New("ゲーム設定の作成に失敗しました")
 	}
 	server.gameSetting = gameSettings
+	if err := logic.ValidateConfig(config); err != nil {
+		return nil, errors.New("設定の検証に失敗しました: " + err.Error())
+	}
 	if config.JSONLogger.Enable {
 		server.jsonLogger = service.NewJSONLogger(config)
 	}
@@ -186,6 +190,10 @@ func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 	}
+	if err := conn.Negotiate(s.config); err != nil {
+		slog.Warn("互換性のないクライアントのため、接続を切断しました", "team_name", conn.TeamName, "error", err)
+		return
+	}
 	s.waitingRoom.AddConnection(conn.TeamName, *conn)
 
 	if s.config.Server.ManualStart {
@@ -194,6 +202,7 @@ func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var game *logic.Game
+	seed := util.ResolveSeed(s.config.Game.Seed)
 	if s.config.Matching.IsOptimize {
 		s.waitingRoom.connections.Range(func(key, value any) bool {
 			team := key.(string)
@@ -206,14 +215,14 @@ func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
 			slog.Error("待機部屋からの接続の取得に失敗しました", "error", err)
 			return
 		}
-		game = logic.NewGameWithRole(&s.config, s.gameSetting, roleMapConns)
+		game = logic.NewGameWithRole(&s.config, s.gameSetting, roleMapConns, seed)
 	} else {
-		connections, err := s.waitingRoom.GetConnections()
+		connections, err := s.waitingRoom.GetConnections(util.NewRand(seed, util.STREAM_MATCHING))
 		if err != nil {
 			slog.Error("待機部屋からの接続の取得に失敗しました", "error", err)
 			return
 		}
-		game = logic.NewGame(&s.config, s.gameSetting, connections)
+		game = logic.NewGame(&s.config, s.gameSetting, connections, seed)
 	}
 	if s.jsonLogger != nil {
 		game.SetJSONLogger(s.jsonLogger)
@@ -231,6 +240,7 @@ func (s *Server) handleConnections(w http.ResponseWriter, r *http.Request) {
 
 	go func() {
 		winSide := game.Start()
+		s.storeUsageReports(game)
 		if s.config.Matching.IsOptimize {
 			if winSide != model.T_NONE {
 				s.matchOptimizer.setMatchEnd(game.GetRoleTeamNamesMap())
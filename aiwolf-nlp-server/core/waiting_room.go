@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log/slog"
 	"math/rand/v2"
+	"slices"
 	"sync"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
@@ -112,7 +113,7 @@ func (wr *WaitingRoom) GetConnectionsWithMatchOptimizer(matches []map[model.Role
 	return roleMapConns, nil
 }
 
-func (wr *WaitingRoom) GetConnections() ([]model.Connection, error) {
+func (wr *WaitingRoom) GetConnections(rng *rand.Rand) ([]model.Connection, error) {
 	connections := []model.Connection{}
 	ready := false
 
@@ -146,7 +147,8 @@ func (wr *WaitingRoom) GetConnections() ([]model.Connection, error) {
 		})
 
 		if len(teams) >= wr.agentCount {
-			rand.Shuffle(len(teams), func(i, j int) {
+			slices.Sort(teams)
+			rng.Shuffle(len(teams), func(i, j int) {
 				teams[i], teams[j] = teams[j], teams[i]
 			})
 
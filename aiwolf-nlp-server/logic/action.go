@@ -0,0 +1,146 @@
+package logic
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+// Action はフェーズ内で実行されるアクションです
+type Action interface {
+	Execute(g *Game)
+}
+
+// ActionFunc は関数をアクションとして扱うための型です
+type ActionFunc func(g *Game)
+
+func (f ActionFunc) Execute(g *Game) {
+	f(g)
+}
+
+// SimultaneousAction は同時実行が有効な夜のフェーズで、他のアクションと同時にリクエストを送信できるアクションです
+// Collect は他のアクションの Collect と並列に呼び出され、返した関数はすべての Collect が終了した後に解決順序で呼び出されます
+type SimultaneousAction interface {
+	Action
+	Collect(g *Game) func()
+}
+
+// SimultaneousActionFunc は単独で実行する関数と、同時に実行する場合にリクエストを送信する関数をアクションとして扱うための型です
+type SimultaneousActionFunc struct {
+	Single   func(g *Game)
+	Parallel func(g *Game) func()
+}
+
+func (f SimultaneousActionFunc) Execute(g *Game) {
+	f.Single(g)
+}
+
+func (f SimultaneousActionFunc) Collect(g *Game) func() {
+	return f.Parallel(g)
+}
+
+// TargetAction は各エージェントに対象を1体指定させるアクションです
+// Validate がエラーを返した場合は Apply を実行しません
+type TargetAction struct {
+	Request  model.Request
+	Actors   func(g *Game) []*model.Agent
+	Validate func(g *Game, agent *model.Agent, target *model.Agent) error
+	Apply    func(g *Game, agent *model.Agent, target *model.Agent)
+}
+
+func (a TargetAction) Execute(g *Game) {
+	for _, agent := range a.Actors(g) {
+		slog.Info("アクションを実行します", "id", g.id, "request", a.Request.String(), "agent", agent.String())
+		target, err := g.findTargetByRequest(agent, a.Request)
+		if err != nil {
+			slog.Warn("対象が見つからなかったため、アクションを実行しません", "id", g.id, "request", a.Request.String(), "agent", agent.String())
+			continue
+		}
+		if a.Validate != nil {
+			if err := a.Validate(g, agent, target); err != nil {
+				slog.Warn("対象が不正であるため、アクションを実行しません", "id", g.id, "request", a.Request.String(), "agent", agent.String(), "target", target.String(), "error", err)
+				continue
+			}
+		}
+		a.Apply(g, agent, target)
+	}
+}
+
+var (
+	actionsMu sync.RWMutex
+	actions   = map[string]Action{
+		"talk":           ActionFunc((*Game).doTalk),
+		"whisper":        ActionFunc((*Game).doWhisper),
+		"mason_talk":     ActionFunc((*Game).doMasonTalk),
+		"graveyard_talk": ActionFunc((*Game).doGraveyardTalk),
+		"execution":      ActionFunc((*Game).doExecution),
+		"divine":         SimultaneousActionFunc{Single: (*Game).doDivine, Parallel: (*Game).collectDivine},
+		"guard":          SimultaneousActionFunc{Single: (*Game).doGuard, Parallel: (*Game).collectGuard},
+		"attack":         SimultaneousActionFunc{Single: (*Game).doAttack, Parallel: (*Game).collectAttack},
+	}
+)
+
+// RegisterAction はフェーズの actions で指定できるアクションを登録します
+// TargetAction を登録した場合は、そのリクエストも登録します
+func RegisterAction(name string, action Action) error {
+	if name == "" || action == nil {
+		return errors.New("アクション名とアクションを指定する必要があります")
+	}
+	actionsMu.Lock()
+	defer actionsMu.Unlock()
+	if _, exists := actions[name]; exists {
+		return errors.New("アクションが既に登録されています: " + name)
+	}
+	if simultaneousAction, ok := action.(SimultaneousActionFunc); ok {
+		if simultaneousAction.Single == nil || simultaneousAction.Parallel == nil {
+			return errors.New("SingleとParallelを指定する必要があります: " + name)
+		}
+	}
+	if targetAction, ok := action.(TargetAction); ok {
+		if targetAction.Actors == nil || targetAction.Apply == nil {
+			return errors.New("ActorsとApplyを指定する必要があります: " + name)
+		}
+		if err := model.RegisterRequest(targetAction.Request); err != nil {
+			return err
+		}
+	}
+	actions[name] = action
+	return nil
+}
+
+func getAction(name string) (Action, bool) {
+	actionsMu.RLock()
+	defer actionsMu.RUnlock()
+	action, exists := actions[name]
+	return action, exists
+}
+
+// isSimultaneousAction は同時に実行できるアクションとして登録されているかを返します
+func isSimultaneousAction(name string) bool {
+	action, exists := getAction(name)
+	if !exists {
+		return false
+	}
+	_, ok := action.(SimultaneousAction)
+	return ok
+}
+
+// ValidateConfig は設定ファイルのフェーズや夜のアクションの解決順序に、未登録のアクションが含まれていないかを検証します
+func ValidateConfig(config model.Config) error {
+	for _, phase := range slices.Concat(config.Logic.DayPhases, config.Logic.NightPhases) {
+		for _, name := range phase.Actions {
+			if _, exists := getAction(name); !exists {
+				return errors.New("[Logic] 不明なアクションです: " + name)
+			}
+		}
+	}
+	for _, name := range config.Game.NightAction.ResolutionOrder {
+		if !isSimultaneousAction(name) {
+			return errors.New("[NightAction] 同時に実行できないアクションです: " + name)
+		}
+	}
+	return nil
+}
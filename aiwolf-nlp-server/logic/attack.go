@@ -5,61 +5,67 @@ import (
 	"log/slog"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
-	"github.com/aiwolfdial/aiwolf-nlp-server/util"
 )
 
-func (g *Game) getAttackVotedCandidates(votes []model.Vote) []model.Agent {
-	return util.GetCandidates(votes, func(vote model.Vote) bool {
-		return vote.Target.Role != model.R_WEREWOLF
+func (g *Game) getAttackVotedCandidates(votes []model.Vote, voters int) ([]model.Agent, bool) {
+	return g.resolveVotes(votes, voters, g.setting.AttackVote.Abstain, g.setting.AttackVote.Quorum, func(vote model.Vote) bool {
+		return !g.hasAbility(&vote.Target, model.A_ATTACK)
 	})
 }
 
 func (g *Game) doAttack() {
+	g.conductAttack(g.executeAttackVote)
+}
+
+// collectAttack は1回目の襲撃投票のリクエストを送信し、受信した投票で襲撃を行う関数を返します
+// 再投票は襲撃を解決する時に行います
+func (g *Game) collectAttack() func() {
+	var attackVotes []model.Vote
+	if len(g.getAliveAgentsWithAbility(model.A_ATTACK)) > 0 {
+		attackVotes = g.collectAttackVotes(0)
+	}
+	return func() {
+		g.conductAttack(func(round int) []model.Vote {
+			if round == 0 {
+				g.getCurrentGameStatus().AttackVotes = append(g.getCurrentGameStatus().AttackVotes, attackVotes...)
+				return attackVotes
+			}
+			return g.executeAttackVote(round)
+		})
+	}
+}
+
+func (g *Game) conductAttack(executeAttackVote func(round int) []model.Vote) {
 	slog.Info("襲撃フェーズを開始します", "id", g.id, "day", g.currentDay)
-	var attacked *model.Agent
-	werewolfs := g.getAliveWerewolves()
+	attacked := make([]model.Agent, 0)
+	werewolfs := g.getAliveAgentsWithAbility(model.A_ATTACK)
 	if len(werewolfs) > 0 {
 		candidates := make([]model.Agent, 0)
-		for range g.setting.AttackVote.MaxCount {
-			g.executeAttackVote()
-			candidates = g.getAttackVotedCandidates(g.getCurrentGameStatus().AttackVotes)
+		for i := range g.setting.AttackVote.MaxCount {
+			if i > 0 && g.setting.AttackVote.Runoff && len(candidates) > 1 {
+				g.getCurrentGameStatus().AttackVoteCandidates = candidates
+				slog.Info("襲撃の決選投票の候補者を設定しました", "id", g.id, "candidates", len(candidates))
+			}
+			voters := len(g.getAliveAgentsWithAbility(model.A_ATTACK))
+			votes := executeAttackVote(i)
+			var cancelled bool
+			candidates, cancelled = g.getAttackVotedCandidates(votes, voters)
+			if cancelled {
+				break
+			}
 			if len(candidates) == 1 {
-				attacked = &candidates[0]
+				attacked = candidates
 				break
 			}
 		}
-		if attacked == nil && !g.setting.AttackVote.AllowNoTarget && len(candidates) > 0 {
-			rand := util.SelectRandomAgent(candidates)
-			attacked = &rand
+		if len(attacked) == 0 && len(candidates) > 0 {
+			attacked = g.breakTie(g.setting.AttackVote.TieBreak, candidates)
 		}
 
-		if attacked != nil && !g.isGuarded(attacked) {
-			g.getCurrentGameStatus().StatusMap[*attacked] = model.S_DEAD
-			g.getCurrentGameStatus().AttackedAgent = attacked
-			if g.gameLogger != nil {
-				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attack,%d,true", g.currentDay, attacked.Idx))
-			}
-			if g.realtimeBroadcaster != nil {
-				packet := g.getRealtimeBroadcastPacket()
-				packet.Event = "襲撃"
-				packet.ToIdx = &attacked.Idx
-				g.realtimeBroadcaster.Broadcast(packet)
-			}
-			slog.Info("襲撃結果を設定しました", "id", g.id, "agent", attacked.String())
-		} else if attacked != nil {
-			if g.gameLogger != nil {
-				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attack,%d,false", g.currentDay, attacked.Idx))
-			}
-			if g.realtimeBroadcaster != nil {
-				packet := g.getRealtimeBroadcastPacket()
-				packet.Event = "襲撃"
-				idx := -1
-				packet.FromIdx = &idx
-				packet.ToIdx = &attacked.Idx
-				g.realtimeBroadcaster.Broadcast(packet)
-			}
-			slog.Info("護衛されたため、襲撃結果を設定しません", "id", g.id, "agent", attacked.String())
-		} else {
+		for i := range attacked {
+			g.attack(&attacked[i])
+		}
+		if len(attacked) == 0 {
 			if g.gameLogger != nil {
 				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attack,-1,true", g.currentDay))
 			}
@@ -74,6 +80,54 @@ func (g *Game) doAttack() {
 	slog.Info("襲撃フェーズを終了します", "id", g.id, "day", g.currentDay)
 }
 
+func (g *Game) attack(attacked *model.Agent) {
+	if g.setting.RoleDefinitions.Get(attacked.Role).ResistAttack {
+		if g.gameLogger != nil {
+			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attack,%d,false", g.currentDay, attacked.Idx))
+		}
+		if g.realtimeBroadcaster != nil {
+			packet := g.getRealtimeBroadcastPacket()
+			packet.Event = "襲撃"
+			packet.ToIdx = &attacked.Idx
+			message := "襲撃が無効になりました"
+			packet.Message = &message
+			g.realtimeBroadcaster.Broadcast(packet)
+		}
+		slog.Info("襲撃対象が襲撃耐性を持つため、襲撃結果を設定しません", "id", g.id, "agent", attacked.String())
+	} else if !g.isGuarded(attacked) {
+		g.getCurrentGameStatus().StatusMap[*attacked] = model.S_DEAD
+		g.getCurrentGameStatus().AttackedAgents = append(g.getCurrentGameStatus().AttackedAgents, *attacked)
+		if g.getCurrentGameStatus().AttackedAgent == nil {
+			g.getCurrentGameStatus().AttackedAgent = attacked
+		}
+		if g.gameLogger != nil {
+			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attack,%d,true", g.currentDay, attacked.Idx))
+		}
+		if g.realtimeBroadcaster != nil {
+			packet := g.getRealtimeBroadcastPacket()
+			packet.Event = "襲撃"
+			packet.ToIdx = &attacked.Idx
+			g.realtimeBroadcaster.Broadcast(packet)
+		}
+		slog.Info("襲撃結果を設定しました", "id", g.id, "agent", attacked.String())
+		g.requestLastWords(attacked)
+	} else {
+		g.getCurrentGameStatus().GuardedAgent = attacked
+		if g.gameLogger != nil {
+			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attack,%d,false", g.currentDay, attacked.Idx))
+		}
+		if g.realtimeBroadcaster != nil {
+			packet := g.getRealtimeBroadcastPacket()
+			packet.Event = "襲撃"
+			idx := -1
+			packet.FromIdx = &idx
+			packet.ToIdx = &attacked.Idx
+			g.realtimeBroadcaster.Broadcast(packet)
+		}
+		slog.Info("護衛されたため、襲撃結果を設定しません", "id", g.id, "agent", attacked.String())
+	}
+}
+
 func (g *Game) isGuarded(attacked *model.Agent) bool {
 	if g.getCurrentGameStatus().Guard == nil {
 		return false
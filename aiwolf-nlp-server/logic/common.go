@@ -3,6 +3,7 @@ package logic
 import (
 	"errors"
 	"log/slog"
+	"sync"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
 	"github.com/aiwolfdial/aiwolf-nlp-server/util"
@@ -13,6 +14,10 @@ func (g *Game) findTargetByRequest(agent *model.Agent, request model.Request) (*
 	if err != nil {
 		return nil, err
 	}
+	return g.findTargetByName(agent, name)
+}
+
+func (g *Game) findTargetByName(agent *model.Agent, name string) (*model.Agent, error) {
 	target := util.FindAgentByName(g.agents, name)
 	if target == nil {
 		return nil, errors.New("対象エージェントが見つかりません")
@@ -20,6 +25,34 @@ func (g *Game) findTargetByRequest(agent *model.Agent, request model.Request) (*
 	slog.Info("対象エージェントを受信しました", "id", g.id, "agent", agent.String(), "target", target.String())
 	return target, nil
 }
+
+type agentResponse struct {
+	text string
+	err  error
+}
+
+// requestToAgents は複数のエージェントに同時にリクエストを送信し、エージェントの順序で応答を返します
+// すべてのリクエストを同時に送信するため、応答は共通の期限内に集められます
+func (g *Game) requestToAgents(agents []*model.Agent, request model.Request) []agentResponse {
+	return g.requestToAgentsWith(agents, request, nil)
+}
+
+// requestToAgentsWith は customize でパケットを変更してから、複数のエージェントに並列にリクエストを送信します
+func (g *Game) requestToAgentsWith(agents []*model.Agent, request model.Request, customize func(packet *model.Packet)) []agentResponse {
+	responses := make([]agentResponse, len(agents))
+	var wg sync.WaitGroup
+	for i, agent := range agents {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			text, err := g.requestToAgentWith(agent, request, customize)
+			responses[i] = agentResponse{text: text, err: err}
+		}()
+	}
+	wg.Wait()
+	return responses
+}
+
 func (g *Game) closeAllAgents() {
 	for _, agent := range g.agents {
 		agent.Close()
@@ -41,10 +74,10 @@ func (g *Game) buildInfo(agent *model.Agent) model.Info {
 	gameStatus := g.getCurrentGameStatus()
 	lastGameStatus := g.gameStatuses[g.currentDay-1]
 	if lastGameStatus != nil {
-		if lastGameStatus.MediumResult != nil && agent.Role == model.R_MEDIUM {
+		if lastGameStatus.MediumResult != nil && g.canSee(agent, model.VF_MEDIUM_RESULT) {
 			info.MediumResult = lastGameStatus.MediumResult
 		}
-		if lastGameStatus.DivineResult != nil && agent.Role == model.R_SEER {
+		if lastGameStatus.DivineResult != nil && g.canSee(agent, model.VF_DIVINE_RESULT) {
 			info.DivineResult = lastGameStatus.DivineResult
 		}
 		if lastGameStatus.ExecutedAgent != nil {
@@ -53,28 +86,57 @@ func (g *Game) buildInfo(agent *model.Agent) model.Info {
 		if lastGameStatus.AttackedAgent != nil {
 			info.AttackedAgent = lastGameStatus.AttackedAgent
 		}
-		if g.setting.VoteVisibility {
+		if lastGameStatus.GuardedAgent != nil && g.canSee(agent, model.VF_GUARDED_AGENT) {
+			info.GuardedAgent = lastGameStatus.GuardedAgent
+		}
+		// 候補者全員を対象にする設定では、対象が1人以下の場合も一覧を送信する
+		if g.setting.Vote.TieBreak == model.TB_ALL || len(lastGameStatus.ExecutedAgents) > 1 {
+			info.ExecutedAgents = lastGameStatus.ExecutedAgents
+			if g.canSee(agent, model.VF_MEDIUM_RESULT) {
+				info.MediumResults = lastGameStatus.MediumResults
+			}
+		}
+		if g.setting.AttackVote.TieBreak == model.TB_ALL || len(lastGameStatus.AttackedAgents) > 1 {
+			info.AttackedAgents = lastGameStatus.AttackedAgents
+		}
+		if g.canSee(agent, model.VF_VOTE_LIST) {
 			info.VoteList = lastGameStatus.Votes
 		}
-		if g.setting.VoteVisibility && agent.Role == model.R_WEREWOLF {
+		if g.canSee(agent, model.VF_ATTACK_VOTE_LIST) {
 			info.AttackVoteList = lastGameStatus.AttackVotes
 		}
 	}
 	info.TalkList = gameStatus.Talks
-	if agent.Role == model.R_WEREWOLF {
+	if g.canSeeChannel(agent, model.C_WHISPER) {
 		info.WhisperList = gameStatus.Whispers
 	}
+	if g.canSeeChannel(agent, model.C_MASON_TALK) {
+		info.MasonTalkList = gameStatus.MasonTalks
+	}
+	if g.canUseGraveyardTalk(agent) {
+		info.GraveyardTalkList = gameStatus.GraveyardTalks
+	}
 	info.StatusMap = gameStatus.StatusMap
-	roleMap := make(map[model.Agent]model.Role)
-	roleMap[*agent] = agent.Role
-	if agent.Role == model.R_WEREWOLF {
+	if g.isSpectator(agent) && g.setting.Spectator.RevealRoles {
+		info.RoleMap = util.GetRoleMap(g.agents)
+	} else {
+		roleMap := make(map[model.Agent]model.Role)
+		roleMap[*agent] = agent.Role
+		definition := g.setting.RoleDefinitions.Get(agent.Role)
 		for a := range gameStatus.StatusMap {
-			if a.Role == model.R_WEREWOLF {
+			if definition.Knows(a.Role) || g.setting.Visibility.Knows(agent.Role, a.Role) {
 				roleMap[a] = a.Role
 			}
 		}
+		if g.canSee(agent, model.VF_EXECUTED_ROLE) {
+			for day := range g.currentDay {
+				for _, executed := range g.gameStatuses[day].ExecutedAgents {
+					roleMap[executed] = executed.Role
+				}
+			}
+		}
+		info.RoleMap = roleMap
 	}
-	info.RoleMap = roleMap
 	if gameStatus.RemainCountMap != nil {
 		count := (*gameStatus.RemainCountMap)[*agent]
 		info.RemainCount = &count
@@ -92,6 +154,15 @@ func (g *Game) buildInfo(agent *model.Agent) model.Info {
 }
 
 func (g *Game) requestToAgent(agent *model.Agent, request model.Request) (string, error) {
+	return g.requestToAgentWith(agent, request, nil)
+}
+
+// requestToAgentWith は customize でパケットを変更してから、エージェントにリクエストを送信します
+// リクエストごとに異なる値をパケットに設定する場合に、ゲームの状態を経由せずに渡すために使用します
+func (g *Game) requestToAgentWith(agent *model.Agent, request model.Request, customize func(packet *model.Packet)) (string, error) {
+	mu := g.getRequestMu(agent)
+	mu.Lock()
+	defer mu.Unlock()
 	info := g.buildInfo(agent)
 	var packet model.Packet
 	switch request {
@@ -103,44 +174,87 @@ func (g *Game) requestToAgent(agent *model.Agent, request model.Request) (string
 		if request == model.R_INITIALIZE {
 			packet.Info.Profile = agent.ProfileDescription
 		}
-	case model.R_VOTE, model.R_DIVINE, model.R_GUARD:
+	case model.R_VOTE, model.R_DIVINE, model.R_GUARD, model.R_LAST_WORDS:
 		packet = model.Packet{Request: &request, Info: &info}
-	case model.R_DAILY_FINISH, model.R_TALK, model.R_WHISPER, model.R_ATTACK:
+		if request == model.R_VOTE {
+			packet.Info.VoteCandidates = g.getCurrentGameStatus().VoteCandidates
+		}
+	case model.R_DAILY_FINISH, model.R_TALK, model.R_WHISPER, model.R_MASON_TALK, model.R_GRAVEYARD_TALK, model.R_ATTACK:
 		packet = model.Packet{Request: &request, Info: &info}
-		talks, whispers := g.minimize(agent, info.TalkList, info.WhisperList)
+		if request == model.R_ATTACK {
+			packet.Info.AttackVoteCandidates = g.getCurrentGameStatus().AttackVoteCandidates
+		}
+		talks, whispers, masonTalks, graveyardTalks := g.minimize(agent, info.TalkList, info.WhisperList, info.MasonTalkList, info.GraveyardTalkList)
 		if request == model.R_TALK || request == model.R_DAILY_FINISH {
 			packet.TalkHistory = &talks
 		}
-		if request == model.R_WHISPER || request == model.R_ATTACK || (request == model.R_DAILY_FINISH && agent.Role == model.R_WEREWOLF) {
+		if request == model.R_WHISPER || request == model.R_ATTACK || (request == model.R_DAILY_FINISH && g.canSeeChannel(agent, model.C_WHISPER)) {
 			packet.WhisperHistory = &whispers
 		}
+		if request == model.R_MASON_TALK || (request == model.R_DAILY_FINISH && g.canSeeChannel(agent, model.C_MASON_TALK)) {
+			packet.MasonTalkHistory = &masonTalks
+		}
+		if request == model.R_GRAVEYARD_TALK || (request == model.R_DAILY_FINISH && g.canUseGraveyardTalk(agent)) {
+			packet.GraveyardTalkHistory = &graveyardTalks
+		}
+	case model.R_BID:
+		packet = model.Packet{Request: &request, Info: &info}
 	case model.R_FINISH:
 		info.RoleMap = util.GetRoleMap(g.agents)
+		info.WinSide = &g.winSide
+		info.ResultReason = &g.resultReason
 		packet = model.Packet{Request: &request, Info: &info}
+		// ゲーム終了直前に追加された遺言は以降のトークで送信されないため、ゲーム終了リクエストのトーク履歴として送信する
+		if talks := g.getCurrentGameStatus().Talks; len(talks) > 0 {
+			packet.TalkHistory = &talks
+		}
 	default:
-		return "", errors.New("一致するリクエストがありません")
+		if !model.IsCustomRequest(request) {
+			return "", errors.New("一致するリクエストがありません")
+		}
+		packet = model.Packet{Request: &request, Info: &info}
+	}
+	if customize != nil {
+		customize(&packet)
 	}
 	if g.jsonLogger != nil {
 		g.jsonLogger.TrackStartRequest(g.id, *agent, packet)
 	}
-	resp, err := agent.SendPacket(packet, g.config.Server.Timeout.Action, g.config.Server.Timeout.Response, g.config.Server.Timeout.Acceptable)
+	resp, response, err := agent.SendPacket(packet, g.config.Server.Timeout.Action, g.config.Server.Timeout.Response, g.config.Server.Timeout.Acceptable)
 	if g.jsonLogger != nil {
-		g.jsonLogger.TrackEndRequest(g.id, *agent, resp, err)
+		g.jsonLogger.TrackEndRequest(g.id, *agent, resp, response, err)
 	}
+	g.recordUsage(agent, response)
 	return resp, err
 }
 
 func (g *Game) resetLastIdxMaps() {
+	g.lastIdxMu.Lock()
+	defer g.lastIdxMu.Unlock()
 	g.lastTalkIdxMap = make(map[*model.Agent]int)
 	g.lastWhisperIdxMap = make(map[*model.Agent]int)
+	g.lastMasonTalkIdxMap = make(map[*model.Agent]int)
+	g.lastGraveyardTalkIdxMap = make(map[*model.Agent]int)
 }
 
-func (g *Game) minimize(agent *model.Agent, talks []model.Talk, whispers []model.Talk) ([]model.Talk, []model.Talk) {
+func (g *Game) minimize(agent *model.Agent, talks []model.Talk, whispers []model.Talk, masonTalks []model.Talk, graveyardTalks []model.Talk) ([]model.Talk, []model.Talk, []model.Talk, []model.Talk) {
+	g.lastIdxMu.Lock()
+	defer g.lastIdxMu.Unlock()
 	lastTalkIdx := g.lastTalkIdxMap[agent]
 	lastWhisperIdx := g.lastWhisperIdxMap[agent]
+	lastMasonTalkIdx := g.lastMasonTalkIdxMap[agent]
+	lastGraveyardTalkIdx := g.lastGraveyardTalkIdxMap[agent]
 	g.lastTalkIdxMap[agent] = len(talks)
 	g.lastWhisperIdxMap[agent] = len(whispers)
-	return talks[lastTalkIdx:], whispers[lastWhisperIdx:]
+	g.lastMasonTalkIdxMap[agent] = len(masonTalks)
+	g.lastGraveyardTalkIdxMap[agent] = len(graveyardTalks)
+	return talks[lastTalkIdx:], whispers[lastWhisperIdx:], masonTalks[lastMasonTalkIdx:], graveyardTalks[lastGraveyardTalkIdx:]
+}
+
+// getRequestMu は同じエージェントへの送信が並列に行われないようにするためのミューテックスを返します
+func (g *Game) getRequestMu(agent *model.Agent) *sync.Mutex {
+	mu, _ := g.requestMuMap.LoadOrStore(agent, &sync.Mutex{})
+	return mu.(*sync.Mutex)
 }
 
 func (g *Game) getCurrentGameStatus() *model.GameStatus {
@@ -153,12 +267,46 @@ func (g *Game) getAliveAgents() []*model.Agent {
 	})
 }
 
-func (g *Game) getAliveWerewolves() []*model.Agent {
+func (g *Game) getAliveAgentsWithAbility(ability model.Ability) []*model.Agent {
 	return util.FilterAgents(g.agents, func(agent *model.Agent) bool {
-		return g.isAlive(agent) && agent.Role.Species == model.S_WEREWOLF
+		return g.isAlive(agent) && g.hasAbility(agent, ability)
 	})
 }
 
+func (g *Game) getAliveAgentsInChannel(channel model.Channel) []*model.Agent {
+	return util.FilterAgents(g.agents, func(agent *model.Agent) bool {
+		return g.isAlive(agent) && g.canUseChannel(agent, channel)
+	})
+}
+
+func (g *Game) hasAbility(agent *model.Agent, ability model.Ability) bool {
+	return g.setting.RoleDefinitions.Get(agent.Role).HasAbility(ability)
+}
+
+func (g *Game) canUseChannel(agent *model.Agent, channel model.Channel) bool {
+	return g.setting.RoleDefinitions.Get(agent.Role).CanUse(channel)
+}
+
+// canSee は可視性の設定に従い、エージェントが情報を参照できるかどうかを返します
+func (g *Game) canSee(agent *model.Agent, field model.VisibilityField) bool {
+	return g.setting.Visibility.CanSee(field, agent.Role)
+}
+
+// canSeeChannel はエージェントが会話の履歴を参照できるかどうかを返します
+// 会話に参加できるエージェントは、可視性の設定にかかわらず履歴を参照できます
+func (g *Game) canSeeChannel(agent *model.Agent, channel model.Channel) bool {
+	if g.canUseChannel(agent, channel) {
+		return true
+	}
+	switch channel {
+	case model.C_WHISPER:
+		return g.canSee(agent, model.VF_WHISPER)
+	case model.C_MASON_TALK:
+		return g.canSee(agent, model.VF_MASON_TALK)
+	}
+	return false
+}
+
 func (g *Game) isAlive(agent *model.Agent) bool {
 	return g.getCurrentGameStatus().StatusMap[*agent] == model.S_ALIVE
 }
@@ -211,12 +359,13 @@ func (g *Game) IsFinished() bool {
 
 // AgentStatusInfo はAPI用のエージェント状態情報です
 type AgentStatusInfo struct {
-	Idx      int    `json:"idx"`
-	Name     string `json:"name"`
-	Team     string `json:"team"`
-	Role     string `json:"role"`
-	Alive    bool   `json:"alive"`
-	HasError bool   `json:"has_error"`
+	Idx      int                  `json:"idx"`
+	Name     string               `json:"name"`
+	Team     string               `json:"team"`
+	Role     string               `json:"role"`
+	Alive    bool                 `json:"alive"`
+	HasError bool                 `json:"has_error"`
+	Outbound *model.OutboundStats `json:"outbound,omitempty"`
 }
 
 // checkPause はフェーズ境界で一時停止をチェックします
@@ -271,6 +420,21 @@ func (g *Game) GetDay() int {
 	return g.currentDay
 }
 
+// GetAliveAgents は生存しているエージェントの一覧を返します
+func (g *Game) GetAliveAgents() []*model.Agent {
+	return g.getAliveAgents()
+}
+
+// GetCurrentGameStatus は現在の日のゲーム状態を返します
+func (g *Game) GetCurrentGameStatus() *model.GameStatus {
+	return g.getCurrentGameStatus()
+}
+
+// GetSetting はゲームの設定を返します
+func (g *Game) GetSetting() *model.Setting {
+	return g.setting
+}
+
 // GetIsDaytime は現在が昼かどうかを返します
 func (g *Game) GetIsDaytime() bool {
 	return g.isDaytime
@@ -291,7 +455,8 @@ func (g *Game) GetAgentStatusInfos() []AgentStatusInfo {
 			Team:     agent.TeamName,
 			Role:     agent.Role.Name,
 			Alive:    g.isAlive(agent),
-			HasError: agent.HasError,
+			HasError: agent.HasError(),
+			Outbound: agent.OutboundStats(),
 		})
 	}
 	return infos
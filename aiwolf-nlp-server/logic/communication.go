@@ -3,7 +3,8 @@ package logic
 import (
 	"fmt"
 	"log/slog"
-	"math/rand"
+	"slices"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -20,6 +21,19 @@ func (g *Game) doWhisper() {
 	}
 }
 
+func (g *Game) doMasonTalk() {
+	if g.setting.MasonTalk == nil {
+		slog.Warn("共有会話の設定がないため、共有会話フェーズをスキップします", "id", g.id, "day", g.currentDay)
+		return
+	}
+	slog.Info("共有会話フェーズを開始します", "id", g.id, "day", g.currentDay)
+	if g.config.Game.Realtime.Enable {
+		g.conductRealtimeCommunication(model.R_MASON_TALK)
+	} else {
+		g.conductCommunication(model.R_MASON_TALK)
+	}
+}
+
 func (g *Game) doTalk() {
 	slog.Info("トークフェーズを開始します", "id", g.id, "day", g.currentDay)
 	if g.config.Game.Realtime.Enable {
@@ -40,9 +54,17 @@ func (g *Game) conductCommunication(request model.Request) {
 		talkSetting = &g.setting.Talk.TalkSetting
 		talkList = &g.getCurrentGameStatus().Talks
 	case model.R_WHISPER:
-		agents = g.getAliveWerewolves()
+		agents = g.getAliveAgentsInChannel(model.C_WHISPER)
 		talkSetting = &g.setting.Whisper.TalkSetting
 		talkList = &g.getCurrentGameStatus().Whispers
+	case model.R_MASON_TALK:
+		agents = g.getAliveAgentsInChannel(model.C_MASON_TALK)
+		talkSetting = g.setting.MasonTalk
+		talkList = &g.getCurrentGameStatus().MasonTalks
+	case model.R_GRAVEYARD_TALK:
+		agents = g.getSpectators()
+		talkSetting = g.setting.Spectator.GraveyardTalk
+		talkList = &g.getCurrentGameStatus().GraveyardTalks
 	default:
 		return
 	}
@@ -65,21 +87,69 @@ func (g *Game) conductCommunication(request model.Request) {
 	g.getCurrentGameStatus().RemainLengthMap = &remainLengthMap
 	g.getCurrentGameStatus().RemainSkipMap = &remainSkipMap
 
-	rand.Shuffle(len(agents), func(i, j int) {
-		agents[i], agents[j] = agents[j], agents[i]
-	})
+	switch talkSetting.Order {
+	case model.TO_SEAT, model.TO_ROTATE:
+		slices.SortFunc(agents, func(a, b *model.Agent) int {
+			return a.Idx - b.Idx
+		})
+	default:
+		g.rng.Shuffle(len(agents), func(i, j int) {
+			agents[i], agents[j] = agents[j], agents[i]
+		})
+	}
+
+	canSpeak := func(agent *model.Agent) bool {
+		if remainCountMap[*agent] <= 0 {
+			return false
+		}
+		if value, exists := remainLengthMap[*agent]; exists {
+			if value <= 0 {
+				return false
+			}
+		}
+		return true
+	}
 
-	idx := 0
-	for i := range talkSetting.MaxCount.PerDay {
+	idx := len(*talkList)
+	lastText := ""
+	var lastSpeaker *model.Agent
+	consecutive := 0
+	// 入札制では1ターンに1回の発言権を与えるため、全員が1回ずつ発言する他の発言順と同じ発言回数になるようにターン数を増やす
+	// 発言可能なエージェントがいなくなった時点で終了する
+	maxTurns := talkSetting.MaxCount.PerDay
+	if talkSetting.Order == model.TO_BID {
+		maxTurns *= len(agents)
+	}
+	for i := range maxTurns {
 		cnt := false
-		for _, agent := range agents {
-			if remainCountMap[*agent] <= 0 {
-				continue
+		var queue []*model.Agent
+		if talkSetting.Order == model.TO_BID {
+			candidates := util.FilterAgents(agents, canSpeak)
+			if len(candidates) == 0 {
+				break
 			}
-			if value, exists := remainLengthMap[*agent]; exists {
-				if value <= 0 {
-					continue
-				}
+			winner := g.grantFloor(request, i, candidates, remainCountMap, lastSpeaker, consecutive, talkSetting.Bid.MaxConsecutive)
+			if winner == lastSpeaker {
+				consecutive++
+			} else {
+				consecutive = 1
+			}
+			lastSpeaker = winner
+			queue = []*model.Agent{winner}
+			// 発言がオーバーであっても、他のエージェントが入札できるため続行する
+			cnt = true
+		} else {
+			queue = g.getTurnOrder(talkSetting.Order, agents, i)
+		}
+		if talkSetting.Order == model.TO_MENTION {
+			queue = prioritizeMentioned(queue, nil, lastText)
+		}
+		position := 0
+		for len(queue) > 0 {
+			agent := queue[0]
+			queue = queue[1:]
+			if !canSpeak(agent) {
+				continue
 			}
 			remainCountMap[*agent]--
 			text := g.getTalkWhisperText(agent, request)
@@ -197,14 +267,20 @@ func (g *Game) conductCommunication(request model.Request) {
 			}
 
 			talk := model.Talk{
-				Idx:   idx,
-				Day:   g.getCurrentGameStatus().Day,
-				Turn:  i,
-				Agent: *agent,
-				Text:  text,
+				Idx:      idx,
+				Day:      g.getCurrentGameStatus().Day,
+				Turn:     i,
+				Position: position,
+				Agent:    *agent,
+				Text:     text,
 			}
 			idx++
+			position++
 			*talkList = append(*talkList, talk)
+			if talkSetting.Order == model.TO_MENTION {
+				lastText = text
+				queue = prioritizeMentioned(queue, agent, text)
+			}
 			if text != model.T_OVER {
 				cnt = true
 			} else {
@@ -212,30 +288,21 @@ func (g *Game) conductCommunication(request model.Request) {
 				slog.Info("発言がオーバーであるため、残り発言回数を0にしました", "id", g.id, "agent", agent.String())
 			}
 			if g.gameLogger != nil {
-				if request == model.R_TALK {
-					g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,talk,%d,%d,%d,%s", g.currentDay, talk.Idx, talk.Turn, talk.Agent.Idx, talk.Text))
-				} else {
-					g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,whisper,%d,%d,%d,%s", g.currentDay, talk.Idx, talk.Turn, talk.Agent.Idx, talk.Text))
-				}
+				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,%s,%d,%d,%d,%s,%d,%s", g.currentDay, talkLogType(request), talk.Idx, talk.Turn, talk.Agent.Idx, talk.Text, talk.Position, talkSetting.Order))
 			}
 			if g.realtimeBroadcaster != nil {
-				if request == model.R_TALK {
-					packet := g.getRealtimeBroadcastPacket()
-					packet.Event = "トーク"
-					packet.Message = &talk.Text
-					packet.BubbleIdx = &agent.Idx
-					g.realtimeBroadcaster.Broadcast(packet)
-				} else {
-					packet := g.getRealtimeBroadcastPacket()
-					packet.Event = "囁き"
-					packet.Message = &talk.Text
-					packet.BubbleIdx = &agent.Idx
-					g.realtimeBroadcaster.Broadcast(packet)
-				}
+				packet := g.getRealtimeBroadcastPacket()
+				packet.Event = talkBroadcastEvent(request)
+				packet.Message = &talk.Text
+				packet.BubbleIdx = &agent.Idx
+				g.realtimeBroadcaster.Broadcast(packet)
 			}
 			if g.ttsBroadcaster != nil {
 				g.ttsBroadcaster.BroadcastText(g.id, talk.Text, agent.Profile.VoiceID)
 			}
+			if request == model.R_TALK {
+				g.broadcastToSpectators(talk)
+			}
 			slog.Info("発言を受信しました", "id", g.id, "agent", agent.String(), "text", text, "count", remainCountMap[*agent], "length", remainLengthMap[*agent], "skip", remainSkipMap[*agent])
 		}
 		if !cnt {
@@ -248,6 +315,85 @@ func (g *Game) conductCommunication(request model.Request) {
 	g.getCurrentGameStatus().RemainSkipMap = nil
 }
 
+// getTurnOrder は発言順の設定に従い、ターンごとの発言順を返します
+func (g *Game) getTurnOrder(order model.TalkOrder, agents []*model.Agent, turn int) []*model.Agent {
+	queue := slices.Clone(agents)
+	switch order {
+	case model.TO_ROTATE:
+		if len(queue) > 0 {
+			shift := turn % len(queue)
+			queue = append(queue[shift:], queue[:shift]...)
+		}
+	case model.TO_RESHUFFLE:
+		g.rng.Shuffle(len(queue), func(i, j int) {
+			queue[i], queue[j] = queue[j], queue[i]
+		})
+	}
+	return queue
+}
+
+// prioritizeMentioned は発言で最初にメンションされたエージェントが、そのターンにまだ発言していない場合に次の発言者にします
+func prioritizeMentioned(queue []*model.Agent, speaker *model.Agent, text string) []*model.Agent {
+	target := -1
+	mentionIdx := -1
+	for i, a := range queue {
+		if a == speaker {
+			continue
+		}
+		idx := strings.Index(text, "@"+a.String())
+		if idx != -1 && (mentionIdx == -1 || idx < mentionIdx) {
+			target = i
+			mentionIdx = idx
+		}
+	}
+	if target <= 0 {
+		return queue
+	}
+	mentioned := queue[target]
+	return slices.Insert(slices.Delete(queue, target, target+1), 0, mentioned)
+}
+
+// grantFloor は発言可能なエージェントに入札をリクエストし、最も高い入札をしたエージェントに発言権を与えます
+// 同じエージェントが連続して発言権を得られる回数は bid.max_consecutive までに制限します
+// 入札が同じ場合は残り発言回数が多いエージェントを優先し、それも同じ場合はランダムに決定します
+func (g *Game) grantFloor(request model.Request, turn int, candidates []*model.Agent, remainCountMap map[model.Agent]int, lastSpeaker *model.Agent, consecutive int, maxConsecutive int) *model.Agent {
+	candidates = slices.Clone(candidates)
+	if maxConsecutive > 0 && consecutive >= maxConsecutive && len(candidates) > 1 {
+		candidates = slices.DeleteFunc(candidates, func(agent *model.Agent) bool {
+			return agent == lastSpeaker
+		})
+	}
+	g.rng.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	responses := g.requestToAgentsWith(candidates, model.R_BID, func(packet *model.Packet) {
+		packet.BidRequest = &request
+	})
+
+	var winner *model.Agent
+	winnerBid := -1
+	for i, agent := range candidates {
+		bid := 0
+		if responses[i].err != nil {
+			slog.Warn("入札の受信に失敗したため、入札を0として扱います", "id", g.id, "agent", agent.String(), "error", responses[i].err)
+		} else if value, err := strconv.Atoi(strings.TrimSpace(responses[i].text)); err != nil {
+			slog.Warn("入札が整数ではないため、入札を0として扱います", "id", g.id, "agent", agent.String(), "bid", responses[i].text)
+		} else {
+			bid = max(value, 0)
+		}
+		if g.gameLogger != nil {
+			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,bid,%s,%d,%d,%d", g.currentDay, talkLogType(request), turn, agent.Idx, bid))
+		}
+		if bid > winnerBid || (bid == winnerBid && remainCountMap[*agent] > remainCountMap[*winner]) {
+			winner = agent
+			winnerBid = bid
+		}
+	}
+	slog.Info("発言権を与えました", "id", g.id, "agent", winner.String(), "bid", winnerBid)
+	return winner
+}
+
 func (g *Game) getTalkWhisperText(agent *model.Agent, request model.Request) string {
 	text, err := g.requestToAgent(agent, request)
 	if text == model.T_FORCE_SKIP {
@@ -260,3 +406,27 @@ func (g *Game) getTalkWhisperText(agent *model.Agent, request model.Request) str
 	}
 	return text
 }
+
+func talkLogType(request model.Request) string {
+	switch request {
+	case model.R_WHISPER:
+		return "whisper"
+	case model.R_MASON_TALK:
+		return "masonTalk"
+	case model.R_GRAVEYARD_TALK:
+		return "graveyardTalk"
+	}
+	return "talk"
+}
+
+func talkBroadcastEvent(request model.Request) string {
+	switch request {
+	case model.R_WHISPER:
+		return "囁き"
+	case model.R_MASON_TALK:
+		return "共有会話"
+	case model.R_GRAVEYARD_TALK:
+		return "墓場チャット"
+	}
+	return "トーク"
+}
@@ -9,18 +9,44 @@ import (
 
 func (g *Game) doDivine() {
 	slog.Info("占いフェーズを開始します", "id", g.id, "day", g.currentDay)
-	for _, agent := range g.getAliveAgents() {
-		if agent.Role == model.R_SEER {
-			g.conductDivination(agent)
-			break
-		}
+	if agent := g.getDivineAgent(); agent != nil {
+		slog.Info("占いアクションを開始します", "id", g.id, "agent", agent.String())
+		target, err := g.findTargetByRequest(agent, model.R_DIVINE)
+		g.conductDivination(agent, target, err)
 	}
 	slog.Info("占いフェーズを終了します", "id", g.id, "day", g.currentDay)
 }
 
-func (g *Game) conductDivination(agent *model.Agent) {
-	slog.Info("占いアクションを開始します", "id", g.id, "agent", agent.String())
-	target, err := g.findTargetByRequest(agent, model.R_DIVINE)
+// collectDivine は占いのリクエストを送信し、受信した対象で占い結果を設定する関数を返します
+func (g *Game) collectDivine() func() {
+	agent := g.getDivineAgent()
+	var target *model.Agent
+	var err error
+	if agent != nil {
+		target, err = g.findTargetByRequest(agent, model.R_DIVINE)
+	}
+	return func() {
+		slog.Info("占いフェーズを開始します", "id", g.id, "day", g.currentDay)
+		if agent != nil {
+			g.conductDivination(agent, target, err)
+		}
+		slog.Info("占いフェーズを終了します", "id", g.id, "day", g.currentDay)
+	}
+}
+
+func (g *Game) getDivineAgent() *model.Agent {
+	agents := g.getAliveAgentsWithAbility(model.A_DIVINE)
+	if len(agents) == 0 {
+		return nil
+	}
+	return agents[0]
+}
+
+func (g *Game) conductDivination(agent *model.Agent, target *model.Agent, err error) {
+	if !g.isAlive(agent) {
+		slog.Warn("占い師が死亡しているため、占い結果を設定しません", "id", g.id, "agent", agent.String())
+		return
+	}
 	if err != nil {
 		slog.Warn("占い対象が見つからなかったため、占い結果を設定しません", "id", g.id)
 		return
@@ -50,4 +76,21 @@ func (g *Game) conductDivination(agent *model.Agent) {
 		g.realtimeBroadcaster.Broadcast(packet)
 	}
 	slog.Info("占い結果を設定しました", "id", g.id, "target", target.String(), "result", target.Role.Species)
+	if g.setting.RoleDefinitions.Get(target.Role).CursedByDivine {
+		g.curse(target)
+	}
+}
+
+func (g *Game) curse(target *model.Agent) {
+	g.getCurrentGameStatus().StatusMap[*target] = model.S_DEAD
+	if g.gameLogger != nil {
+		g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,curse,%d", g.currentDay, target.Idx))
+	}
+	if g.realtimeBroadcaster != nil {
+		packet := g.getRealtimeBroadcastPacket()
+		packet.Event = "呪殺"
+		packet.ToIdx = &target.Idx
+		g.realtimeBroadcaster.Broadcast(packet)
+	}
+	slog.Info("呪殺対象が占われたため、呪殺しました", "id", g.id, "target", target.String())
 }
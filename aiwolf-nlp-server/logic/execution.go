@@ -5,53 +5,42 @@ import (
 	"log/slog"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
-	"github.com/aiwolfdial/aiwolf-nlp-server/util"
 )
 
-func (g *Game) getVotedCandidates(votes []model.Vote) []model.Agent {
-	return util.GetCandidates(votes, func(vote model.Vote) bool {
+func (g *Game) getVotedCandidates(votes []model.Vote, voters int) ([]model.Agent, bool) {
+	return g.resolveVotes(votes, voters, g.setting.Vote.Abstain, g.setting.Vote.Quorum, func(vote model.Vote) bool {
 		return true
 	})
 }
 
 func (g *Game) doExecution() {
 	slog.Info("追放フェーズを開始します", "id", g.id, "day", g.currentDay)
-	var executed *model.Agent
+	executed := make([]model.Agent, 0)
 	candidates := make([]model.Agent, 0)
-	for range g.setting.Vote.MaxCount {
-		g.executeVote()
-		candidates = g.getVotedCandidates(g.getCurrentGameStatus().Votes)
+	for i := range g.setting.Vote.MaxCount {
+		if i > 0 && g.setting.Vote.Runoff && len(candidates) > 1 {
+			g.getCurrentGameStatus().VoteCandidates = candidates
+			slog.Info("決選投票の候補者を設定しました", "id", g.id, "candidates", len(candidates))
+		}
+		voters := len(g.getAliveAgents())
+		votes := g.executeVote(i)
+		var cancelled bool
+		candidates, cancelled = g.getVotedCandidates(votes, voters)
+		if cancelled {
+			break
+		}
 		if len(candidates) == 1 {
-			executed = &candidates[0]
+			executed = candidates
 			break
 		}
 	}
-	if executed == nil && len(candidates) > 0 {
-		rand := util.SelectRandomAgent(candidates)
-		executed = &rand
+	if len(executed) == 0 && len(candidates) > 0 {
+		executed = g.breakTie(g.setting.Vote.TieBreak, candidates)
 	}
-	if executed != nil {
-		g.getCurrentGameStatus().StatusMap[*executed] = model.S_DEAD
-		g.getCurrentGameStatus().ExecutedAgent = executed
-		if g.gameLogger != nil {
-			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,execute,%d,%s", g.currentDay, executed.Idx, executed.Role.Name))
-		}
-		if g.realtimeBroadcaster != nil {
-			packet := g.getRealtimeBroadcastPacket()
-			packet.Event = "追放"
-			packet.ToIdx = &executed.Idx
-			g.realtimeBroadcaster.Broadcast(packet)
-		}
-		slog.Info("追放結果を設定しました", "id", g.id, "agent", executed.String())
-
-		g.getCurrentGameStatus().MediumResult = &model.Judge{
-			Day:    g.getCurrentGameStatus().Day,
-			Agent:  *executed,
-			Target: *executed,
-			Result: executed.Role.Species,
-		}
-		slog.Info("霊能結果を設定しました", "id", g.id, "target", executed.String(), "result", executed.Role.Species)
-	} else {
+	for i := range executed {
+		g.execute(&executed[i])
+	}
+	if len(executed) == 0 {
 		if g.realtimeBroadcaster != nil {
 			packet := g.getRealtimeBroadcastPacket()
 			packet.Event = "追放"
@@ -61,3 +50,36 @@ func (g *Game) doExecution() {
 	}
 	slog.Info("追放フェーズを終了します", "id", g.id, "day", g.currentDay)
 }
+
+func (g *Game) execute(executed *model.Agent) {
+	g.getCurrentGameStatus().StatusMap[*executed] = model.S_DEAD
+	g.getCurrentGameStatus().ExecutedAgents = append(g.getCurrentGameStatus().ExecutedAgents, *executed)
+	if g.gameLogger != nil {
+		g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,execute,%d,%s", g.currentDay, executed.Idx, executed.Role.Name))
+	}
+	if g.realtimeBroadcaster != nil {
+		packet := g.getRealtimeBroadcastPacket()
+		packet.Event = "追放"
+		packet.ToIdx = &executed.Idx
+		g.realtimeBroadcaster.Broadcast(packet)
+	}
+	g.requestLastWords(executed)
+
+	judge := model.Judge{
+		Day:    g.getCurrentGameStatus().Day,
+		Agent:  *executed,
+		Target: *executed,
+		Result: executed.Role.Species,
+	}
+	g.getCurrentGameStatus().MediumResults = append(g.getCurrentGameStatus().MediumResults, judge)
+	if g.getCurrentGameStatus().ExecutedAgent != nil {
+		slog.Info("追放結果を追加しました", "id", g.id, "agent", executed.String())
+		slog.Info("霊能結果を追加しました", "id", g.id, "target", executed.String(), "result", executed.Role.Species)
+		return
+	}
+	g.getCurrentGameStatus().ExecutedAgent = executed
+	slog.Info("追放結果を設定しました", "id", g.id, "agent", executed.String())
+
+	g.getCurrentGameStatus().MediumResult = &judge
+	slog.Info("霊能結果を設定しました", "id", g.id, "target", executed.String(), "result", executed.Role.Species)
+}
@@ -3,6 +3,8 @@ package logic
 import (
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"slices"
 	"sync"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
@@ -13,8 +15,11 @@ import (
 
 type Game struct {
 	id                           string
+	seed                         int64
+	rng                          *rand.Rand
 	agents                       []*model.Agent
 	winSide                      model.Team
+	resultReason                 model.ResultReason
 	isFinished                   bool
 	config                       *model.Config
 	setting                      *model.Setting
@@ -23,6 +28,11 @@ type Game struct {
 	gameStatuses                 map[int]*model.GameStatus
 	lastTalkIdxMap               map[*model.Agent]int
 	lastWhisperIdxMap            map[*model.Agent]int
+	lastMasonTalkIdxMap          map[*model.Agent]int
+	lastGraveyardTalkIdxMap      map[*model.Agent]int
+	lastIdxMu                    sync.Mutex
+	pendingLastWords             []model.Talk
+	lastNightDeaths              int
 	jsonLogger                   *service.JSONLogger
 	gameLogger                   *service.GameLogger
 	realtimeBroadcaster          *service.RealtimeBroadcaster
@@ -33,81 +43,102 @@ type Game struct {
 	paused                       bool
 	pauseMu                      sync.Mutex
 	pauseCond                    *sync.Cond
+	requestMuMap                 sync.Map
+	usageMap                     map[*model.Agent]*AgentUsage
+	usageMu                      sync.Mutex
 }
 
-func NewGame(config *model.Config, settings *model.Setting, conns []model.Connection) *Game {
+func NewGame(config *model.Config, settings *model.Setting, conns []model.Connection, seed int64) *Game {
 	id := ulid.Make().String()
+	rng := util.NewRand(seed, util.STREAM_GAME)
 	var agents []*model.Agent
 	if config.CustomProfile.Enable {
 		if config.CustomProfile.DynamicProfile.Enable {
 			profiles, err := util.GenerateProfiles(config.CustomProfile.DynamicProfile, config.CustomProfile.ProfileEncoding, config.Game.AgentCount)
 			if err != nil {
 				slog.Error("プロフィールの生成に失敗したため、カスタムプロフィールを使用します", "error", err)
-				agents = util.CreateAgentsWithProfiles(conns, settings.RoleNumMap, config.CustomProfile.Profiles, config.CustomProfile.ProfileEncoding)
+				agents = util.CreateAgentsWithProfiles(rng, conns, settings.RoleNumMap, config.CustomProfile.Profiles, config.CustomProfile.ProfileEncoding)
 			} else {
-				agents = util.CreateAgentsWithProfiles(conns, settings.RoleNumMap, profiles, config.CustomProfile.ProfileEncoding)
+				agents = util.CreateAgentsWithProfiles(rng, conns, settings.RoleNumMap, profiles, config.CustomProfile.ProfileEncoding)
 			}
 		} else {
-			agents = util.CreateAgentsWithProfiles(conns, settings.RoleNumMap, config.CustomProfile.Profiles, config.CustomProfile.ProfileEncoding)
+			agents = util.CreateAgentsWithProfiles(rng, conns, settings.RoleNumMap, config.CustomProfile.Profiles, config.CustomProfile.ProfileEncoding)
 		}
 	} else {
-		agents = util.CreateAgents(conns, settings.RoleNumMap)
+		agents = util.CreateAgents(rng, conns, settings.RoleNumMap)
+	}
+	for _, agent := range agents {
+		agent.StartInbound()
+		agent.StartOutbound(settings.Outbound)
 	}
 	gameStatus := model.NewInitializeGameStatus(agents)
 	gameStatuses := make(map[int]*model.GameStatus)
 	gameStatuses[0] = &gameStatus
-	slog.Info("ゲームを作成しました", "id", id)
+	slog.Info("ゲームを作成しました", "id", id, "seed", seed)
 	g := &Game{
-		id:                id,
-		agents:            agents,
-		winSide:           model.T_NONE,
-		isFinished:        false,
-		config:            config,
-		setting:           settings,
-		currentDay:        0,
-		isDaytime:         true,
-		gameStatuses:      gameStatuses,
-		lastTalkIdxMap:    make(map[*model.Agent]int),
-		lastWhisperIdxMap: make(map[*model.Agent]int),
+		id:                      id,
+		seed:                    seed,
+		rng:                     rng,
+		agents:                  agents,
+		winSide:                 model.T_NONE,
+		isFinished:              false,
+		config:                  config,
+		setting:                 settings,
+		currentDay:              0,
+		isDaytime:               true,
+		gameStatuses:            gameStatuses,
+		lastTalkIdxMap:          make(map[*model.Agent]int),
+		lastWhisperIdxMap:       make(map[*model.Agent]int),
+		lastMasonTalkIdxMap:     make(map[*model.Agent]int),
+		lastGraveyardTalkIdxMap: make(map[*model.Agent]int),
 	}
 	g.pauseCond = sync.NewCond(&g.pauseMu)
 	return g
 }
 
-func NewGameWithRole(config *model.Config, settings *model.Setting, roleMapConns map[model.Role][]model.Connection) *Game {
+func NewGameWithRole(config *model.Config, settings *model.Setting, roleMapConns map[model.Role][]model.Connection, seed int64) *Game {
 	id := ulid.Make().String()
+	rng := util.NewRand(seed, util.STREAM_GAME)
 	var agents []*model.Agent
 	if config.CustomProfile.Enable {
 		if config.CustomProfile.DynamicProfile.Enable {
 			profiles, err := util.GenerateProfiles(config.CustomProfile.DynamicProfile, config.CustomProfile.ProfileEncoding, config.Game.AgentCount)
 			if err != nil {
 				slog.Error("プロフィールの生成に失敗したため、カスタムプロフィールを使用します", "error", err)
-				agents = util.CreateAgentsWithRoleAndProfile(roleMapConns, config.CustomProfile.Profiles, config.CustomProfile.ProfileEncoding)
+				agents = util.CreateAgentsWithRoleAndProfile(rng, roleMapConns, config.CustomProfile.Profiles, config.CustomProfile.ProfileEncoding)
 			} else {
-				agents = util.CreateAgentsWithRoleAndProfile(roleMapConns, profiles, config.CustomProfile.ProfileEncoding)
+				agents = util.CreateAgentsWithRoleAndProfile(rng, roleMapConns, profiles, config.CustomProfile.ProfileEncoding)
 			}
 		} else {
-			agents = util.CreateAgentsWithRoleAndProfile(roleMapConns, config.CustomProfile.Profiles, config.CustomProfile.ProfileEncoding)
+			agents = util.CreateAgentsWithRoleAndProfile(rng, roleMapConns, config.CustomProfile.Profiles, config.CustomProfile.ProfileEncoding)
 		}
 	} else {
-		agents = util.CreateAgentsWithRole(roleMapConns)
+		agents = util.CreateAgentsWithRole(rng, roleMapConns)
+	}
+	for _, agent := range agents {
+		agent.StartInbound()
+		agent.StartOutbound(settings.Outbound)
 	}
 	gameStatus := model.NewInitializeGameStatus(agents)
 	gameStatuses := make(map[int]*model.GameStatus)
 	gameStatuses[0] = &gameStatus
-	slog.Info("ゲームを作成しました", "id", id)
+	slog.Info("ゲームを作成しました", "id", id, "seed", seed)
 	g := &Game{
-		id:                id,
-		agents:            agents,
-		winSide:           model.T_NONE,
-		isFinished:        false,
-		config:            config,
-		setting:           settings,
-		currentDay:        0,
-		isDaytime:         true,
-		gameStatuses:      gameStatuses,
-		lastTalkIdxMap:    make(map[*model.Agent]int),
-		lastWhisperIdxMap: make(map[*model.Agent]int),
+		id:                      id,
+		seed:                    seed,
+		rng:                     rng,
+		agents:                  agents,
+		winSide:                 model.T_NONE,
+		isFinished:              false,
+		config:                  config,
+		setting:                 settings,
+		currentDay:              0,
+		isDaytime:               true,
+		gameStatuses:            gameStatuses,
+		lastTalkIdxMap:          make(map[*model.Agent]int),
+		lastWhisperIdxMap:       make(map[*model.Agent]int),
+		lastMasonTalkIdxMap:     make(map[*model.Agent]int),
+		lastGraveyardTalkIdxMap: make(map[*model.Agent]int),
 	}
 	g.pauseCond = sync.NewCond(&g.pauseMu)
 	return g
@@ -116,10 +147,11 @@ func NewGameWithRole(config *model.Config, settings *model.Setting, roleMapConns
 func (g *Game) Start() model.Team {
 	slog.Info("ゲームを開始します", "id", g.id)
 	if g.jsonLogger != nil {
-		g.jsonLogger.TrackStartGame(g.id, g.agents)
+		g.jsonLogger.TrackStartGame(g.id, g.seed, g.agents)
 	}
 	if g.gameLogger != nil {
 		g.gameLogger.TrackStartGame(g.id, g.agents)
+		g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,seed,%d", g.currentDay, g.seed))
 	}
 	if g.realtimeBroadcaster != nil {
 		g.realtimeBroadcaster.TrackStartGame(g.id, g.agents)
@@ -132,6 +164,7 @@ func (g *Game) Start() model.Team {
 		packet.Event = "開始"
 		message := "ゲームが開始されました"
 		packet.Message = &message
+		packet.Seed = &g.seed
 		g.realtimeBroadcaster.Broadcast(packet)
 	}
 	if g.ttsBroadcaster != nil {
@@ -147,8 +180,13 @@ func (g *Game) Start() model.Team {
 		g.gameStatuses[g.currentDay+1] = &gameStatus
 		g.currentDay++
 		slog.Info("日付が進みました", "id", g.id, "day", g.currentDay)
+		g.appendLastWords()
 		if g.config.Game.MaxDay >= 0 && g.currentDay >= g.config.Game.MaxDay+1 {
-			slog.Info("最大日数に達したため、ゲームを終了します", "id", g.id, "day", g.currentDay)
+			if !g.shouldFinish() {
+				g.winSide = util.CalcMaxDayWinSideTeam(g.getCurrentGameStatus().StatusMap, g.setting.MaxDayOutcome)
+				g.resultReason = model.RR_MAX_DAY
+			}
+			slog.Info("最大日数に達したため、ゲームを終了します", "id", g.id, "day", g.currentDay, "winSide", g.winSide)
 			break
 		}
 		if g.shouldFinish() {
@@ -161,7 +199,7 @@ func (g *Game) Start() model.Team {
 			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,status,%d,%s,%s,%s,%s", g.currentDay, agent.Idx, agent.Role.Name, g.getCurrentGameStatus().StatusMap[*agent].String(), agent.OriginalName, agent.GameName))
 		}
 		villagers, werewolves := util.CountAliveTeams(g.getCurrentGameStatus().StatusMap)
-		g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,result,%d,%d,%s", g.currentDay, villagers, werewolves, g.winSide))
+		g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,result,%d,%d,%s,%s", g.currentDay, villagers, werewolves, g.winSide, g.resultReason))
 	}
 	if g.realtimeBroadcaster != nil {
 		packet := g.getRealtimeBroadcastPacket()
@@ -175,7 +213,7 @@ func (g *Game) Start() model.Team {
 	}
 	g.closeAllAgents()
 	if g.jsonLogger != nil {
-		g.jsonLogger.TrackEndGame(g.id, g.winSide)
+		g.jsonLogger.TrackEndGame(g.id, g.winSide, g.resultReason)
 	}
 	if g.gameLogger != nil {
 		g.gameLogger.TrackEndGame(g.id)
@@ -183,7 +221,7 @@ func (g *Game) Start() model.Team {
 	if g.realtimeBroadcaster != nil {
 		g.realtimeBroadcaster.TrackEndGame(g.id)
 	}
-	slog.Info("ゲームが終了しました", "id", g.id, "winSide", g.winSide)
+	slog.Info("ゲームが終了しました", "id", g.id, "winSide", g.winSide, "reason", g.resultReason)
 	g.isFinished = true
 	return g.winSide
 }
@@ -191,9 +229,10 @@ func (g *Game) Start() model.Team {
 func (g *Game) shouldFinish() bool {
 	if util.CalcHasErrorAgents(g.agents) >= int(float64(len(g.agents))*g.config.Server.MaxContinueErrorRatio) {
 		slog.Warn("エラーが多発したため、ゲームを終了します", "id", g.id)
+		g.resultReason = model.RR_ERROR_RATIO
 		return true
 	}
-	g.winSide = util.CalcWinSideTeam(g.getCurrentGameStatus().StatusMap)
+	g.winSide, g.resultReason = util.CalcWinSideTeam(g.getCurrentGameStatus().StatusMap)
 	if g.winSide != model.T_NONE {
 		slog.Info("勝利チームが決定したため、ゲームを終了します", "id", g.id)
 		return true
@@ -212,12 +251,8 @@ func (g *Game) progressDay() {
 	}
 
 	for _, phase := range g.config.Logic.DayPhases {
-		if phase.OnlyDay != nil && *phase.OnlyDay != g.currentDay {
-			slog.Info("実行対象の日ではないため、フェーズをスキップします", "id", g.id, "day", g.currentDay, "phase", phase.Name)
-			continue
-		}
-		if phase.ExceptDay != nil && *phase.ExceptDay == g.currentDay {
-			slog.Info("除外対象の日であるため、フェーズをスキップします", "id", g.id, "day", g.currentDay, "phase", phase.Name)
+		if reason := g.phaseSkipReason(phase); reason != "" {
+			g.skipPhase(phase, reason)
 			continue
 		}
 		g.checkPause()
@@ -236,14 +271,14 @@ func (g *Game) progressNight() {
 	slog.Info("夜セクションを開始します", "id", g.id, "day", g.currentDay)
 	g.isDaytime = false
 	g.requestToEveryone(model.R_DAILY_FINISH)
+	aliveCount := len(g.getAliveAgents())
+	defer func() {
+		g.lastNightDeaths = aliveCount - len(g.getAliveAgents())
+	}()
 
 	for _, phase := range g.config.Logic.NightPhases {
-		if phase.OnlyDay != nil && *phase.OnlyDay != g.currentDay {
-			slog.Info("実行対象の日ではないため、フェーズをスキップします", "id", g.id, "day", g.currentDay, "phase", phase.Name)
-			continue
-		}
-		if phase.ExceptDay != nil && *phase.ExceptDay == g.currentDay {
-			slog.Info("除外対象の日であるため、フェーズをスキップします", "id", g.id, "day", g.currentDay, "phase", phase.Name)
+		if reason := g.phaseSkipReason(phase); reason != "" {
+			g.skipPhase(phase, reason)
 			continue
 		}
 		g.checkPause()
@@ -259,23 +294,20 @@ func (g *Game) progressNight() {
 }
 
 func (g *Game) executePhase(actions []string) {
-	for _, action := range actions {
-		switch action {
-		case "talk":
-			g.doTalk()
-		case "whisper":
-			g.doWhisper()
-		case "execution":
-			g.doExecution()
-		case "divine":
-			g.doDivine()
-		case "guard":
-			g.doGuard()
-		case "attack":
-			g.doAttack()
-		default:
-			slog.Warn("不明なアクションです", "action", action)
+	simultaneous := g.getSimultaneousActions(actions)
+	for _, name := range actions {
+		if slices.Contains(simultaneous, name) {
+			if name == simultaneous[0] {
+				g.doSimultaneousNightActions(simultaneous)
+			}
+			continue
+		}
+		action, exists := getAction(name)
+		if !exists {
+			slog.Warn("不明なアクションです", "action", name)
+			continue
 		}
+		action.Execute(g)
 	}
 }
 
@@ -9,29 +9,62 @@ import (
 
 func (g *Game) doGuard() {
 	slog.Info("護衛フェーズを開始します", "id", g.id, "day", g.currentDay)
-	for _, agent := range g.getAliveAgents() {
-		if agent.Role == model.R_BODYGUARD {
-			g.conductGuard(agent)
-			break
+	if agent := g.getGuardAgent(); agent != nil {
+		slog.Info("護衛アクションを実行します", "id", g.id, "agent", agent.String())
+		target, err := g.findTargetByRequest(agent, model.R_GUARD)
+		g.conductGuard(agent, target, err)
+	}
+}
+
+// collectGuard は護衛のリクエストを送信し、受信した対象で護衛対象を設定する関数を返します
+func (g *Game) collectGuard() func() {
+	agent := g.getGuardAgent()
+	var target *model.Agent
+	var err error
+	if agent != nil {
+		target, err = g.findTargetByRequest(agent, model.R_GUARD)
+	}
+	return func() {
+		slog.Info("護衛フェーズを開始します", "id", g.id, "day", g.currentDay)
+		if agent != nil {
+			g.conductGuard(agent, target, err)
 		}
 	}
 }
 
-func (g *Game) conductGuard(agent *model.Agent) {
-	slog.Info("護衛アクションを実行します", "id", g.id, "agent", agent.String())
-	target, err := g.findTargetByRequest(agent, model.R_GUARD)
+func (g *Game) getGuardAgent() *model.Agent {
+	agents := g.getAliveAgentsWithAbility(model.A_GUARD)
+	if len(agents) == 0 {
+		return nil
+	}
+	return agents[0]
+}
+
+func (g *Game) conductGuard(agent *model.Agent, target *model.Agent, err error) {
+	if !g.isAlive(agent) {
+		slog.Warn("騎士が死亡しているため、護衛対象を設定しません", "id", g.id, "agent", agent.String())
+		return
+	}
 	if err != nil {
-		slog.Warn("護衛対象が見つからなかったため、護衛対象を設定しません", "id", g.id)
+		g.rejectGuard(agent, nil, model.GE_NOT_FOUND)
 		return
 	}
 	if !g.isAlive(target) {
-		slog.Warn("護衛対象が死亡しているため、護衛対象を設定しません", "id", g.id, "target", target.String())
+		g.rejectGuard(agent, target, model.GE_DEAD_TARGET)
 		return
 	}
-	if agent == target {
-		slog.Warn("護衛対象が自分自身であるため、護衛対象を設定しません", "id", g.id, "target", target.String())
+	if agent == target && !g.setting.Guard.AllowSelfGuard {
+		g.rejectGuard(agent, target, model.GE_SELF_GUARD)
 		return
 	}
+	if g.setting.Guard.BanConsecutiveGuard {
+		if lastGameStatus := g.gameStatuses[g.currentDay-1]; lastGameStatus != nil && lastGameStatus.Guard != nil {
+			if lastGameStatus.Guard.Agent == *agent && lastGameStatus.Guard.Target == *target {
+				g.rejectGuard(agent, target, model.GE_CONSECUTIVE_GUARD)
+				return
+			}
+		}
+	}
 	g.getCurrentGameStatus().Guard = &model.Guard{
 		Day:    g.getCurrentGameStatus().Day,
 		Agent:  *agent,
@@ -49,3 +82,23 @@ func (g *Game) conductGuard(agent *model.Agent) {
 	}
 	slog.Info("護衛対象を設定しました", "id", g.id, "target", target.String())
 }
+
+func (g *Game) rejectGuard(agent *model.Agent, target *model.Agent, reason model.GuardErrorReason) {
+	guardError := model.GuardError{
+		Day:    g.getCurrentGameStatus().Day,
+		Agent:  *agent,
+		Target: target,
+		Reason: reason,
+	}
+	if g.gameLogger != nil {
+		targetIdx := -1
+		if target != nil {
+			targetIdx = target.Idx
+		}
+		g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,guardError,%d,%d,%s", g.currentDay, agent.Idx, targetIdx, reason))
+	}
+	if g.jsonLogger != nil {
+		g.jsonLogger.TrackError(g.id, *agent, guardError)
+	}
+	slog.Warn("護衛対象が不正であるため、護衛対象を設定しません", "id", g.id, "agent", agent.String(), "reason", reason, "error", guardError.Error())
+}
@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"fmt"
+	"log/slog"
+	"unicode/utf8"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
+)
+
+func (g *Game) requestLastWords(agent *model.Agent) {
+	if g.setting.LastWords == nil {
+		return
+	}
+	slog.Info("遺言をリクエストします", "id", g.id, "agent", agent.String())
+	text, err := g.requestToAgent(agent, model.R_LAST_WORDS)
+	if err != nil {
+		slog.Warn("リクエストの送受信に失敗したため、遺言を設定しません", "id", g.id, "agent", agent.String())
+		return
+	}
+	if text == model.T_OVER || text == model.T_SKIP || text == model.T_FORCE_SKIP {
+		slog.Info("遺言がスキップされたため、遺言を設定しません", "id", g.id, "agent", agent.String())
+		return
+	}
+	if g.setting.LastWords.MaxLength != nil {
+		countInWord := g.config.Game.Talk.MaxLength.CountInWord
+		countSpaces := g.config.Game.Talk.MaxLength.CountSpaces
+		if util.CountLength(text, countInWord, countSpaces) > *g.setting.LastWords.MaxLength {
+			text = util.TrimLength(text, *g.setting.LastWords.MaxLength, countInWord, countSpaces)
+			slog.Warn("遺言が最大文字数を超えたため、切り捨てました", "id", g.id, "agent", agent.String())
+		}
+	}
+	if utf8.RuneCountInString(text) == 0 {
+		slog.Info("遺言が空であるため、遺言を設定しません", "id", g.id, "agent", agent.String())
+		return
+	}
+	g.pendingLastWords = append(g.pendingLastWords, model.Talk{
+		Agent:     *agent,
+		Text:      text,
+		LastWords: true,
+	})
+	slog.Info("遺言を受信しました", "id", g.id, "agent", agent.String(), "text", text)
+}
+
+func (g *Game) appendLastWords() {
+	talkList := &g.getCurrentGameStatus().Talks
+	for _, talk := range g.pendingLastWords {
+		talk.Idx = len(*talkList)
+		talk.Day = g.getCurrentGameStatus().Day
+		*talkList = append(*talkList, talk)
+		if g.gameLogger != nil {
+			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,lastWords,%d,%d,%d,%s", g.currentDay, talk.Idx, talk.Turn, talk.Agent.Idx, talk.Text))
+		}
+		if g.realtimeBroadcaster != nil {
+			packet := g.getRealtimeBroadcastPacket()
+			packet.Event = "遺言"
+			packet.Message = &talk.Text
+			packet.BubbleIdx = &talk.Agent.Idx
+			g.realtimeBroadcaster.Broadcast(packet)
+		}
+		if g.ttsBroadcaster != nil {
+			g.ttsBroadcaster.BroadcastText(g.id, talk.Text, talk.Agent.Profile.VoiceID)
+		}
+		slog.Info("遺言をトーク履歴に追加しました", "id", g.id, "agent", talk.Agent.String(), "text", talk.Text)
+	}
+	g.pendingLastWords = nil
+}
@@ -0,0 +1,128 @@
+package logic
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+// モデレーターのデフォルト値
+const (
+	defaultNudgeAfter      = 10 * time.Second
+	defaultMaxNudges       = 1
+	moderatorCheckInterval = 500 * time.Millisecond
+)
+
+// moderator はリアルタイム通信で沈黙しているエージェントやメンションされたエージェントに TALK_PROMPT を送信して発言を促します
+type moderator struct {
+	game       *Game
+	request    model.Request
+	nudgeAfter time.Duration
+	maxNudges  int
+	nudgeCount map[*model.Agent]int
+	lastActive map[*model.Agent]time.Time
+}
+
+// newModerator はモデレーターが有効な場合にモデレーターを作成します
+// 無効な場合は nil を返します
+func (g *Game) newModerator(request model.Request, agents []*model.Agent) *moderator {
+	config := g.config.Game.Realtime.Moderator
+	if !config.Enable {
+		return nil
+	}
+	nudgeAfter := config.NudgeAfter
+	if nudgeAfter <= 0 {
+		nudgeAfter = defaultNudgeAfter
+		slog.Warn("nudge_afterが未設定のため、デフォルト値を適用します", "id", g.id, "default", nudgeAfter)
+	}
+	maxNudges := config.MaxNudges
+	if maxNudges <= 0 {
+		maxNudges = defaultMaxNudges
+		slog.Warn("max_nudgesが未設定のため、デフォルト値を適用します", "id", g.id, "default", maxNudges)
+	}
+	m := &moderator{
+		game:       g,
+		request:    request,
+		nudgeAfter: nudgeAfter,
+		maxNudges:  maxNudges,
+		nudgeCount: make(map[*model.Agent]int),
+		lastActive: make(map[*model.Agent]time.Time),
+	}
+	now := time.Now()
+	for _, agent := range agents {
+		m.lastActive[agent] = now
+	}
+	return m
+}
+
+// touch はエージェントが発言した時刻を記録します
+func (m *moderator) touch(agent *model.Agent) {
+	m.lastActive[agent] = time.Now()
+}
+
+// nudgeSilent は nudge_after 以上発言していないエージェントに発言を促し、促したエージェント数を返します
+// force が true の場合は、沈黙の時間に関わらず促すことができる全エージェントに発言を促します
+func (m *moderator) nudgeSilent(agents []*model.Agent, canSpeak func(agent *model.Agent) bool, remainCount map[*model.Agent]int, force bool) int {
+	nudged := 0
+	for _, agent := range agents {
+		if !force && time.Since(m.lastActive[agent]) < m.nudgeAfter {
+			continue
+		}
+		if m.nudge(agent, model.PR_SILENCE, canSpeak, remainCount) {
+			nudged++
+		}
+	}
+	return nudged
+}
+
+// nudgeMentioned は発言でメンションされたエージェントに発言を促します
+func (m *moderator) nudgeMentioned(talk model.Talk, agents []*model.Agent, canSpeak func(agent *model.Agent) bool, remainCount map[*model.Agent]int) {
+	for _, agent := range agents {
+		if agent.String() == talk.Agent.String() || !strings.Contains(talk.Text, "@"+agent.String()) {
+			continue
+		}
+		m.nudge(agent, model.PR_MENTION, canSpeak, remainCount)
+	}
+}
+
+func (m *moderator) nudge(agent *model.Agent, reason model.PromptReason, canSpeak func(agent *model.Agent) bool, remainCount map[*model.Agent]int) bool {
+	if !canSpeak(agent) || m.nudgeCount[agent] >= m.maxNudges {
+		return false
+	}
+	g := m.game
+	request := model.R_TALK_PROMPT
+	rc := remainCount[agent]
+	packet := model.Packet{
+		Request: &request,
+		Info: &model.Info{
+			GameID:      g.id,
+			Day:         g.currentDay,
+			Agent:       agent,
+			RemainCount: &rc,
+		},
+		PromptReason: &reason,
+	}
+	if err := agent.SendNonBlocking(packet); err != nil {
+		slog.Error("発言催促の送信に失敗しました", "id", g.id, "agent", agent.String(), "error", err)
+		return false
+	}
+	m.nudgeCount[agent]++
+	m.lastActive[agent] = time.Now()
+	slog.Info("発言を促しました", "id", g.id, "agent", agent.String(), "reason", reason, "count", m.nudgeCount[agent])
+
+	if g.gameLogger != nil {
+		g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,nudge,%s,%d,%s", g.currentDay, talkLogType(m.request), agent.Idx, reason))
+	}
+	if g.realtimeBroadcaster != nil {
+		packet := g.getRealtimeBroadcastPacket()
+		packet.Event = "発言催促"
+		message := reason.String()
+		packet.Message = &message
+		packet.ToIdx = &agent.Idx
+		g.realtimeBroadcaster.Broadcast(packet)
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// 同時実行が有効な夜のフェーズでは、同時に実行できるアクションを解決順序に並べて返す
+// 解決順序に含まれないアクションは、フェーズの actions の順序で最後に解決する
+func (g *Game) getSimultaneousActions(actions []string) []string {
+	if !g.setting.NightAction.Simultaneous || g.isDaytime {
+		return nil
+	}
+	ordered := make([]string, 0)
+	for _, name := range slices.Concat(g.setting.NightAction.ResolutionOrder, actions) {
+		if slices.Contains(actions, name) && !slices.Contains(ordered, name) && isSimultaneousAction(name) {
+			ordered = append(ordered, name)
+		}
+	}
+	if len(ordered) < 2 {
+		return nil
+	}
+	return ordered
+}
+
+func (g *Game) doSimultaneousNightActions(actions []string) {
+	slog.Info("夜のアクションを同時に実行します", "id", g.id, "day", g.currentDay, "actions", actions)
+	resolvers := make([]func(), len(actions))
+	var wg sync.WaitGroup
+	for i, name := range actions {
+		action, exists := getAction(name)
+		if !exists {
+			continue
+		}
+		simultaneousAction, ok := action.(SimultaneousAction)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resolvers[i] = simultaneousAction.Collect(g)
+		}()
+	}
+	wg.Wait()
+	slog.Info("夜のアクションの対象を受信したため、解決します", "id", g.id, "day", g.currentDay)
+
+	for _, resolve := range resolvers {
+		if resolve != nil {
+			resolve()
+		}
+	}
+}
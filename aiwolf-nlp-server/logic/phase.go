@@ -0,0 +1,61 @@
+package logic
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+// フェーズを実行しない場合は、その理由を返す
+func (g *Game) phaseSkipReason(phase model.Phase) string {
+	if phase.OnlyDay != nil && *phase.OnlyDay != g.currentDay {
+		return "実行対象の日ではありません"
+	}
+	if phase.ExceptDay != nil && *phase.ExceptDay == g.currentDay {
+		return "除外対象の日です"
+	}
+	if phase.DayRange != nil {
+		if phase.DayRange.Min != nil && g.currentDay < *phase.DayRange.Min {
+			return "実行対象の日の範囲より前です"
+		}
+		if phase.DayRange.Max != nil && g.currentDay > *phase.DayRange.Max {
+			return "実行対象の日の範囲より後です"
+		}
+	}
+	if phase.EveryNDays != nil && g.currentDay%*phase.EveryNDays != 0 {
+		return strconv.Itoa(*phase.EveryNDays) + "日ごとの実行対象の日ではありません"
+	}
+	alive := len(g.getAliveAgents())
+	if phase.MinAlive != nil && alive < *phase.MinAlive {
+		return "生存者数が最小生存者数を下回っています"
+	}
+	if phase.MaxAlive != nil && alive > *phase.MaxAlive {
+		return "生存者数が最大生存者数を上回っています"
+	}
+	if phase.RoleAlive != nil && !g.isRoleAlive(*phase.RoleAlive) {
+		return *phase.RoleAlive + "が生存していません"
+	}
+	if phase.SkipIfNoDeath && g.lastNightDeaths == 0 {
+		return "昨夜の死亡者がいません"
+	}
+	return ""
+}
+
+// フェーズをスキップしたことを、理由とともにゲームログにも記録する
+func (g *Game) skipPhase(phase model.Phase, reason string) {
+	slog.Info("実行条件を満たさないため、フェーズをスキップします", "id", g.id, "day", g.currentDay, "phase", phase.Name, "reason", reason)
+	if g.gameLogger != nil {
+		g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,skipPhase,%s,%s", g.currentDay, phase.Name, reason))
+	}
+}
+
+func (g *Game) isRoleAlive(name string) bool {
+	for _, agent := range g.getAliveAgents() {
+		if agent.Role.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package logic
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+// 適応的なフェーズ時間のデフォルト値
+const (
+	defaultActiveWindow = 10 * time.Second
+	defaultExtension    = 30 * time.Second
+)
+
+// phaseClock はリアルタイム通信のフェーズの締め切りと残り時間の通知を管理します
+type phaseClock struct {
+	deadline     time.Time
+	hardCap      time.Time
+	adaptive     bool
+	activeWindow time.Duration
+	extension    time.Duration
+	pending      []time.Duration
+	thresholds   []time.Duration
+	timer        *time.Timer
+	noticeTimer  *time.Timer
+}
+
+// newPhaseClock はフェーズの締め切りを設定し、締め切りと次の残り時間の通知のタイマーを開始します
+func (g *Game) newPhaseClock(phaseTimeout time.Duration) *phaseClock {
+	config := g.config.Game.Realtime
+	start := time.Now()
+	c := &phaseClock{
+		deadline:    start.Add(phaseTimeout),
+		timer:       time.NewTimer(phaseTimeout),
+		noticeTimer: time.NewTimer(phaseTimeout),
+	}
+	if config.Adaptive.Enable {
+		c.adaptive = true
+		c.activeWindow = config.Adaptive.ActiveWindow
+		if c.activeWindow <= 0 {
+			c.activeWindow = defaultActiveWindow
+			slog.Warn("active_windowが未設定のため、デフォルト値を適用します", "id", g.id, "default", c.activeWindow)
+		}
+		c.extension = config.Adaptive.Extension
+		if c.extension <= 0 {
+			c.extension = defaultExtension
+			slog.Warn("extensionが未設定のため、デフォルト値を適用します", "id", g.id, "default", c.extension)
+		}
+		maxDuration := config.Adaptive.MaxDuration
+		if maxDuration < phaseTimeout {
+			maxDuration = phaseTimeout * 2
+			slog.Warn("max_durationが未設定またはphase_timeoutより短いため、デフォルト値を適用します", "id", g.id, "default", maxDuration)
+		}
+		c.hardCap = start.Add(maxDuration)
+	}
+	c.thresholds = slices.Clone(config.RemainingTimeNotices)
+	slices.Sort(c.thresholds)
+	slices.Reverse(c.thresholds)
+	c.schedule()
+	return c
+}
+
+// schedule は残り時間より短い通知を次の通知として設定し直します
+func (c *phaseClock) schedule() {
+	remaining := time.Until(c.deadline)
+	c.pending = slices.DeleteFunc(slices.Clone(c.thresholds), func(threshold time.Duration) bool {
+		return threshold <= 0 || threshold >= remaining
+	})
+	c.noticeTimer.Stop()
+	if len(c.pending) > 0 {
+		c.noticeTimer.Reset(remaining - c.pending[0])
+	}
+}
+
+// notified は通知を送信した後に、次の通知を設定します
+func (c *phaseClock) notified() {
+	if len(c.pending) == 0 {
+		return
+	}
+	c.pending = c.pending[1:]
+	if len(c.pending) > 0 {
+		c.noticeTimer.Reset(max(time.Until(c.deadline)-c.pending[0], 0))
+	}
+}
+
+// extend は締め切りの直前まで発言が続いている場合に、上限を超えない範囲で締め切りを延長します
+// 延長した場合は true を返します
+func (c *phaseClock) extend(lastTalkTime time.Time) bool {
+	if !c.adaptive || lastTalkTime.IsZero() || time.Since(lastTalkTime) > c.activeWindow {
+		return false
+	}
+	if !c.deadline.Before(c.hardCap) {
+		return false
+	}
+	c.deadline = c.deadline.Add(c.extension)
+	if c.deadline.After(c.hardCap) {
+		c.deadline = c.hardCap
+	}
+	c.timer.Reset(time.Until(c.deadline))
+	c.schedule()
+	return true
+}
+
+// remaining は締め切りまでの残り時間をミリ秒で返します
+func (c *phaseClock) remaining() int {
+	return int(max(time.Until(c.deadline), 0).Milliseconds())
+}
+
+func (c *phaseClock) stop() {
+	c.timer.Stop()
+	c.noticeTimer.Stop()
+}
+
+// sendRemainingTime は全エージェントに TALK_REMAINING_TIME で締め切りと残り時間を通知します
+func (g *Game) sendRemainingTime(agents []*model.Agent, clock *phaseClock, remainCount map[*model.Agent]int) {
+	request := model.R_TALK_REMAINING_TIME
+	deadline := clock.deadline
+	remaining := clock.remaining()
+	for _, agent := range agents {
+		rc := remainCount[agent]
+		packet := model.Packet{
+			Request: &request,
+			Info: &model.Info{
+				GameID:      g.id,
+				Day:         g.currentDay,
+				Agent:       agent,
+				RemainCount: &rc,
+			},
+			Deadline:      &deadline,
+			RemainingTime: &remaining,
+		}
+		if err := agent.SendNonBlocking(packet); err != nil {
+			slog.Error("残り時間の通知の送信に失敗しました", "id", g.id, "agent", agent.String(), "error", err)
+		}
+	}
+	slog.Info("残り時間を通知しました", "id", g.id, "remaining", remaining, "deadline", deadline)
+}
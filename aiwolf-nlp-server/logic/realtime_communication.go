@@ -3,8 +3,6 @@ package logic
 import (
 	"fmt"
 	"log/slog"
-	"net"
-	"strings"
 	"sync"
 	"time"
 	"unicode/utf8"
@@ -14,8 +12,9 @@ import (
 
 // realtimeMessage はエージェントからのリアルタイムメッセージを表します
 type realtimeMessage struct {
-	agent *model.Agent
-	text  string
+	agent    *model.Agent
+	text     string
+	response *model.Response
 }
 
 // リアルタイム通信のデフォルト値
@@ -23,6 +22,7 @@ const (
 	defaultPhaseTimeout   = 120 * time.Second
 	defaultSilenceTimeout = 30 * time.Second
 	defaultDrainTimeout   = 2 * time.Second
+	defaultDrainQuiet     = 200 * time.Millisecond
 )
 
 // conductRealtimeCommunication はリアルタイム（グループチャット方式）でトーク/ウィスパーを行います
@@ -44,12 +44,19 @@ func (g *Game) conductRealtimeCommunication(request model.Request) {
 		broadcastRequest = model.R_TALK_BROADCAST
 		endRequest = model.R_TALK_END
 	case model.R_WHISPER:
-		agents = g.getAliveWerewolves()
+		agents = g.getAliveAgentsInChannel(model.C_WHISPER)
 		talkSetting = &g.setting.Whisper.TalkSetting
 		talkList = &g.getCurrentGameStatus().Whispers
 		startRequest = model.R_WHISPER_START
 		broadcastRequest = model.R_WHISPER_BROADCAST
 		endRequest = model.R_WHISPER_END
+	case model.R_MASON_TALK:
+		agents = g.getAliveAgentsInChannel(model.C_MASON_TALK)
+		talkSetting = g.setting.MasonTalk
+		talkList = &g.getCurrentGameStatus().MasonTalks
+		startRequest = model.R_MASON_TALK_START
+		broadcastRequest = model.R_MASON_TALK_BROADCAST
+		endRequest = model.R_MASON_TALK_END
 	default:
 		return
 	}
@@ -86,21 +93,24 @@ func (g *Game) conductRealtimeCommunication(request model.Request) {
 	totalTalkCount := 0
 	perDay := talkSetting.MaxCount.PerDay
 
+	// 締め切りをフェーズ開始の通知に含めるため、開始の通知より前にタイマーを開始する
+	clock := g.newPhaseClock(phaseTimeoutDuration)
+	defer clock.stop()
+	deadline := clock.deadline
+	var lastTalkTime time.Time
+
 	// 全エージェントにフェーズ開始を通知（ゲーム状態を含む）
 	for _, agent := range agents {
 		info := g.buildInfo(agent)
 		rc := remainCount[agent]
 		info.RemainCount = &rc
 		startPacket := model.Packet{
-			Request: &startRequest,
-			Info:    &info,
-			Setting: g.setting,
-		}
-		if request == model.R_TALK {
-			startPacket.TalkHistory = talkList
-		} else {
-			startPacket.WhisperHistory = talkList
+			Request:  &startRequest,
+			Info:     &info,
+			Setting:  g.setting,
+			Deadline: &deadline,
 		}
+		setTalkHistory(&startPacket, request, talkList)
 		if err := agent.SendNonBlocking(startPacket); err != nil {
 			slog.Error("フェーズ開始パケットの送信に失敗しました", "id", g.id, "agent", agent.String(), "error", err)
 		}
@@ -109,28 +119,49 @@ func (g *Game) conductRealtimeCommunication(request model.Request) {
 	// メッセージ受信チャネルと終了シグナル
 	msgChan := make(chan realtimeMessage, 100)
 	done := make(chan struct{})
-	var wg sync.WaitGroup
 
-	// 各エージェントのリスナーgoroutineを起動
+	// 各エージェントの受信をリスナー経由でメッセージ受信チャネルに振り分ける
 	for _, agent := range agents {
-		wg.Add(1)
-		go g.startAgentListener(agent, msgChan, done, &wg)
+		agent.Listen(func(text string, response *model.Response) {
+			g.recordUsage(agent, response)
+			select {
+			case msgChan <- realtimeMessage{agent: agent, text: text, response: response}:
+			case <-done:
+			}
+		})
 	}
 
 	// メインイベントループ
-	phaseTimeout := time.After(phaseTimeoutDuration)
 	silenceTimer := time.NewTimer(silenceTimeoutDuration)
 	defer silenceTimer.Stop()
 
 	idx := len(*talkList) // 既存のトークの続きからインデックスを開始
+	startIdx := idx
 	rateLimit := g.config.Game.Realtime.RateLimit
 
+	// モデレーターが無効な場合は、nil チャネルにより発言催促を行わない
+	moderator := g.newModerator(request, agents)
+	var moderatorTick <-chan time.Time
+	if moderator != nil {
+		moderatorTicker := time.NewTicker(moderatorCheckInterval)
+		defer moderatorTicker.Stop()
+		moderatorTick = moderatorTicker.C
+	}
+	canSpeak := func(agent *model.Agent) bool {
+		return !agent.HasError() && !overMap[agent] && remainCount[agent] > 0
+	}
+
 loop:
 	for {
 		select {
 		case msg := <-msgChan:
+			// 構造化されたレスポンスの理由を含め、受信したメッセージをJSONログに記録する
+			if g.jsonLogger != nil {
+				g.jsonLogger.TrackEndRequest(g.id, *msg.agent, msg.text, msg.response, nil)
+			}
+
 			// エラー状態またはOVER済みのエージェントからのメッセージは無視
-			if msg.agent.HasError || overMap[msg.agent] {
+			if msg.agent.HasError() || overMap[msg.agent] {
 				continue
 			}
 
@@ -190,6 +221,10 @@ loop:
 			totalTalkCount++
 
 			lastSpeakTime[msg.agent] = time.Now()
+			lastTalkTime = lastSpeakTime[msg.agent]
+			if moderator != nil {
+				moderator.touch(msg.agent)
+			}
 
 			// サイレンスタイマーをリセット
 			if !silenceTimer.Stop() {
@@ -201,12 +236,14 @@ loop:
 			silenceTimer.Reset(silenceTimeoutDuration)
 
 			// トークエントリを作成
+			// リアルタイムモードでは到着順を発言位置とする
 			talk := model.Talk{
-				Idx:   idx,
-				Day:   g.currentDay,
-				Turn:  0,
-				Agent: *msg.agent,
-				Text:  text,
+				Idx:      idx,
+				Day:      g.currentDay,
+				Turn:     0,
+				Position: idx - startIdx,
+				Agent:    *msg.agent,
+				Text:     text,
 			}
 			idx++
 			*talkList = append(*talkList, talk)
@@ -220,11 +257,7 @@ loop:
 				broadcastPacket := model.Packet{
 					Request: &broadcastRequest,
 				}
-				if request == model.R_TALK {
-					broadcastPacket.TalkHistory = &broadcastTalks
-				} else {
-					broadcastPacket.WhisperHistory = &broadcastTalks
-				}
+				setTalkHistory(&broadcastPacket, request, &broadcastTalks)
 				// 受信者の残り回数を通知
 				broadcastPacket.Info = &model.Info{
 					GameID:      g.id,
@@ -237,23 +270,19 @@ loop:
 				}
 			}
 
+			if request == model.R_TALK {
+				g.broadcastToSpectators(talk)
+			}
+
 			// ログ記録
 			if g.gameLogger != nil {
-				if request == model.R_TALK {
-					g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,talk,%d,%d,%d,%s", g.currentDay, talk.Idx, talk.Turn, talk.Agent.Idx, talk.Text))
-				} else {
-					g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,whisper,%d,%d,%d,%s", g.currentDay, talk.Idx, talk.Turn, talk.Agent.Idx, talk.Text))
-				}
+				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,%s,%d,%d,%d,%s,%d,realtime", g.currentDay, talkLogType(request), talk.Idx, talk.Turn, talk.Agent.Idx, talk.Text, talk.Position))
 			}
 
 			// リアルタイムブロードキャスター（ビューア用）
 			if g.realtimeBroadcaster != nil {
 				packet := g.getRealtimeBroadcastPacket()
-				if request == model.R_TALK {
-					packet.Event = "トーク"
-				} else {
-					packet.Event = "囁き"
-				}
+				packet.Event = talkBroadcastEvent(request)
 				packet.Message = &talk.Text
 				packet.BubbleIdx = &msg.agent.Idx
 				g.realtimeBroadcaster.Broadcast(packet)
@@ -264,25 +293,52 @@ loop:
 				g.ttsBroadcaster.BroadcastText(g.id, talk.Text, msg.agent.Profile.VoiceID)
 			}
 
+			if moderator != nil {
+				moderator.nudgeMentioned(talk, agents, canSpeak, remainCount)
+			}
+
 			// #4: PerDay上限到達チェック
 			if perDay > 0 && totalTalkCount >= perDay {
 				slog.Info("全体の発言合計数が上限に達したため、フェーズを終了します", "id", g.id, "totalTalkCount", totalTalkCount)
 				break loop
 			}
 
-		case <-phaseTimeout:
+		case <-clock.timer.C:
+			if clock.extend(lastTalkTime) {
+				slog.Info("発言が続いているため、フェーズを延長しました", "id", g.id, "deadline", clock.deadline)
+				if g.gameLogger != nil {
+					g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,extend,%s,%d", g.currentDay, talkLogType(request), clock.remaining()))
+				}
+				g.sendRemainingTime(agents, clock, remainCount)
+				continue
+			}
 			slog.Info("フェーズタイムアウトに達したため、フェーズを終了します", "id", g.id)
 			break loop
 
+		case <-clock.noticeTimer.C:
+			g.sendRemainingTime(agents, clock, remainCount)
+			clock.notified()
+
+		case <-moderatorTick:
+			moderator.nudgeSilent(agents, canSpeak, remainCount, false)
+
 		case <-silenceTimer.C:
+			// モデレーターが有効な場合は、発言を促せるエージェントがいなくなるまでフェーズを終了しない
+			if moderator != nil && moderator.nudgeSilent(agents, canSpeak, remainCount, true) > 0 {
+				slog.Info("サイレンスタイムアウトに達したため、発言を促しました", "id", g.id)
+				silenceTimer.Reset(silenceTimeoutDuration)
+				continue
+			}
 			slog.Info("サイレンスタイムアウトに達したため、フェーズを終了します", "id", g.id)
 			break loop
 		}
 	}
 
-	// リスナーgoroutineを停止
+	// リスナーを解除し、以降の受信は破棄する
 	close(done)
-	wg.Wait()
+	for _, agent := range agents {
+		agent.Unlisten()
+	}
 
 	// 全エージェントにフェーズ終了を通知
 	for _, agent := range agents {
@@ -294,55 +350,40 @@ loop:
 		}
 	}
 
-	// #1: stale message対策 - WebSocketバッファに残った未読メッセージをドレインする
+	// #1: stale message対策 - TALK_END送信後に遅れて届くメッセージが途切れるまで待つ
 	// TALK_END送信後、エージェントがTALK_ENDを受信・処理するまでの間に
-	// 送信されたメッセージがバッファに残る可能性がある。
-	// これを読み捨てないと、次のフェーズ（VOTE等）で SendPacket の ReadMessage が
+	// 送信されたメッセージが届く可能性がある。
+	// 応答待ちのリクエストがない間の受信は破棄されるが、待たずに次のフェーズ（VOTE等）に進むと
 	// staleメッセージをレスポンスとして誤読してしまう。
 	g.drainAgentBuffers(agents)
 
 	// lastIdxMapを更新（DAILY_FINISHで差分が空になるようにする）
+	// 観戦者への配信やリクエストの送信と同じマップを更新するため、ロックを取得する
+	g.lastIdxMu.Lock()
 	for _, agent := range agents {
-		if request == model.R_TALK {
+		switch request {
+		case model.R_TALK:
 			g.lastTalkIdxMap[agent] = len(*talkList)
-		} else {
+		case model.R_WHISPER:
 			g.lastWhisperIdxMap[agent] = len(*talkList)
+		case model.R_MASON_TALK:
+			g.lastMasonTalkIdxMap[agent] = len(*talkList)
 		}
 	}
+	g.lastIdxMu.Unlock()
 
 	slog.Info("リアルタイム通信フェーズを終了しました", "id", g.id, "day", g.currentDay, "totalTalks", len(*talkList))
 }
 
-// startAgentListener は1エージェント分のWebSocket読み取りgoroutineです
-func (g *Game) startAgentListener(agent *model.Agent, msgChan chan<- realtimeMessage, done <-chan struct{}, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for {
-		select {
-		case <-done:
-			agent.Connection.SetReadDeadline(time.Time{}) // デッドラインをクリア
-			return
-		default:
-			// 短いデッドラインを設定してdoneチャネルを定期的にチェックできるようにする
-			agent.Connection.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
-			_, msg, err := agent.Connection.ReadMessage()
-			if err != nil {
-				// タイムアウトの場合はdoneをチェックして再試行
-				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-					continue
-				}
-				// 本当のエラー
-				slog.Warn("リアルタイムリスナーでエラーが発生しました", "id", g.id, "agent", agent.String(), "error", err)
-				agent.HasError = true
-				return
-			}
-			text := strings.TrimRight(string(msg), "\n")
-			select {
-			case msgChan <- realtimeMessage{agent: agent, text: text}:
-			case <-done:
-				agent.Connection.SetReadDeadline(time.Time{})
-				return
-			}
-		}
+// setTalkHistory はリクエストの種類に応じたチャネルの履歴をパケットに設定します
+func setTalkHistory(packet *model.Packet, request model.Request, talks *[]model.Talk) {
+	switch request {
+	case model.R_TALK:
+		packet.TalkHistory = talks
+	case model.R_WHISPER:
+		packet.WhisperHistory = talks
+	case model.R_MASON_TALK:
+		packet.MasonTalkHistory = talks
 	}
 }
 
@@ -356,39 +397,20 @@ func (g *Game) allOver(overMap map[*model.Agent]bool) bool {
 	return true
 }
 
-// drainAgentBuffers はフェーズ終了後にWebSocketバッファに残った
-// staleメッセージを読み捨てます。
-// 各エージェントに対して短いデッドラインでReadMessageを繰り返し、
-// タイムアウトするまでバッファ内のメッセージを消費します。
+// drainAgentBuffers はフェーズ終了後に遅れて届いた
+// staleメッセージが途切れるまで待ちます。
+// 応答待ちのリクエストとリスナーがない間に受信したメッセージは読み取りgoroutineが破棄するため、
+// 各エージェントについて短い間メッセージが届かなくなるまで待ちます。
 func (g *Game) drainAgentBuffers(agents []*model.Agent) {
 	var wg sync.WaitGroup
 	for _, agent := range agents {
-		if agent.HasError {
+		if agent.HasError() {
 			continue
 		}
 		wg.Add(1)
 		go func(a *model.Agent) {
 			defer wg.Done()
-			drained := 0
-			deadline := time.Now().Add(defaultDrainTimeout)
-			for time.Now().Before(deadline) {
-				a.Connection.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
-				_, msg, err := a.Connection.ReadMessage()
-				if err != nil {
-					if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-						// タイムアウト = バッファが空になった
-						break
-					}
-					// 接続エラー
-					slog.Warn("ドレイン中にエラーが発生しました", "id", g.id, "agent", a.String(), "error", err)
-					break
-				}
-				drained++
-				text := strings.TrimRight(string(msg), "\n")
-				slog.Info("staleメッセージをドレインしました", "id", g.id, "agent", a.String(), "text", text)
-			}
-			// デッドラインをクリアして次のフェーズに備える
-			a.Connection.SetReadDeadline(time.Time{})
+			drained := a.Drain(defaultDrainQuiet, defaultDrainTimeout)
 			if drained > 0 {
 				slog.Info("ドレイン完了", "id", g.id, "agent", a.String(), "drained", drained)
 			}
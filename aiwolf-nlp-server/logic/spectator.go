@@ -0,0 +1,65 @@
+package logic
+
+import (
+	"log/slog"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
+)
+
+// isSpectator は観戦モードが有効な場合に、エージェントが観戦者 (死亡したエージェント) かどうかを返します
+func (g *Game) isSpectator(agent *model.Agent) bool {
+	return g.setting.Spectator != nil && !g.isAlive(agent)
+}
+
+func (g *Game) canUseGraveyardTalk(agent *model.Agent) bool {
+	return g.isSpectator(agent) && g.setting.Spectator.GraveyardTalk != nil
+}
+
+func (g *Game) getSpectators() []*model.Agent {
+	return util.FilterAgents(g.agents, func(agent *model.Agent) bool {
+		return g.isSpectator(agent) && !agent.HasError()
+	})
+}
+
+func (g *Game) doGraveyardTalk() {
+	if g.setting.Spectator == nil || g.setting.Spectator.GraveyardTalk == nil {
+		slog.Warn("墓場チャットの設定がないため、墓場チャットフェーズをスキップします", "id", g.id, "day", g.currentDay)
+		return
+	}
+	slog.Info("墓場チャットフェーズを開始します", "id", g.id, "day", g.currentDay)
+	// 墓場チャットはリアルタイムモードでもターン制で行う
+	g.conductCommunication(model.R_GRAVEYARD_TALK)
+}
+
+// broadcastToSpectators は観戦者にトークを TALK_BROADCAST で送信します
+// 送信したトークは DAILY_FINISH などのトーク履歴に再度含めません
+func (g *Game) broadcastToSpectators(talk model.Talk) {
+	if g.setting.Spectator == nil {
+		return
+	}
+	request := model.R_TALK_BROADCAST
+	for _, agent := range g.getSpectators() {
+		talks := []model.Talk{talk}
+		packet := model.Packet{
+			Request: &request,
+			Info: &model.Info{
+				GameID: g.id,
+				Day:    g.currentDay,
+				Agent:  agent,
+			},
+			TalkHistory: &talks,
+		}
+		mu := g.getRequestMu(agent)
+		mu.Lock()
+		err := agent.SendNonBlocking(packet)
+		mu.Unlock()
+		if err != nil {
+			slog.Error("観戦者へのブロードキャストの送信に失敗しました", "id", g.id, "agent", agent.String(), "error", err)
+			continue
+		}
+		g.lastIdxMu.Lock()
+		g.lastTalkIdxMap[agent] = talk.Idx + 1
+		g.lastIdxMu.Unlock()
+	}
+}
@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+// AgentUsage はAPI用のエージェントごとのトークン使用量です
+// 構造化されたレスポンスに含まれるトークン数をゲーム中に集計します
+type AgentUsage struct {
+	Idx          int    `json:"idx"`
+	Agent        string `json:"agent"`
+	Team         string `json:"team"`
+	Model        string `json:"model"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+	CallCount    int    `json:"call_count"`
+}
+
+// recordUsage は構造化されたレスポンスに含まれるトークン数とモデル名を集計します
+func (g *Game) recordUsage(agent *model.Agent, response *model.Response) {
+	if response == nil || (response.Usage == nil && response.Model == nil) {
+		return
+	}
+	g.usageMu.Lock()
+	defer g.usageMu.Unlock()
+	if g.usageMap == nil {
+		g.usageMap = make(map[*model.Agent]*AgentUsage)
+	}
+	usage, exists := g.usageMap[agent]
+	if !exists {
+		usage = &AgentUsage{
+			Idx:   agent.Idx,
+			Agent: agent.String(),
+			Team:  agent.TeamName,
+		}
+		g.usageMap[agent] = usage
+	}
+	if response.Model != nil {
+		usage.Model = *response.Model
+	}
+	if response.Usage != nil {
+		usage.InputTokens += response.Usage.InputTokens
+		usage.OutputTokens += response.Usage.OutputTokens
+		usage.CallCount++
+	}
+}
+
+// GetAgentUsages はエージェントごとのトークン使用量をエージェントのインデックス順に返します
+func (g *Game) GetAgentUsages() []AgentUsage {
+	g.usageMu.Lock()
+	defer g.usageMu.Unlock()
+	var usages []AgentUsage
+	for _, agent := range g.agents {
+		if usage, exists := g.usageMap[agent]; exists {
+			usages = append(usages, *usage)
+		}
+	}
+	return usages
+}
@@ -3,27 +3,57 @@ package logic
 import (
 	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/aiwolfdial/aiwolf-nlp-server/util"
 )
 
-func (g *Game) executeVote() {
-	slog.Info("投票アクションを開始します", "id", g.id, "day", g.currentDay)
-	g.getCurrentGameStatus().Votes = g.collectVotes(model.R_VOTE, g.getAliveAgents())
+func (g *Game) executeVote(round int) []model.Vote {
+	slog.Info("投票アクションを開始します", "id", g.id, "day", g.currentDay, "round", round)
+	votes := g.collectVotes(model.R_VOTE, g.getAliveAgents(), g.getCurrentGameStatus().VoteCandidates, round)
+	g.getCurrentGameStatus().Votes = append(g.getCurrentGameStatus().Votes, votes...)
+	return votes
 }
 
-func (g *Game) executeAttackVote() {
-	slog.Info("襲撃投票アクションを開始します", "id", g.id, "day", g.currentDay)
-	g.getCurrentGameStatus().AttackVotes = g.collectVotes(model.R_ATTACK, g.getAliveWerewolves())
+func (g *Game) executeAttackVote(round int) []model.Vote {
+	votes := g.collectAttackVotes(round)
+	g.getCurrentGameStatus().AttackVotes = append(g.getCurrentGameStatus().AttackVotes, votes...)
+	return votes
 }
 
-func (g *Game) collectVotes(request model.Request, agents []*model.Agent) []model.Vote {
+func (g *Game) collectAttackVotes(round int) []model.Vote {
+	slog.Info("襲撃投票アクションを開始します", "id", g.id, "day", g.currentDay, "round", round)
+	return g.collectVotes(model.R_ATTACK, g.getAliveAgentsWithAbility(model.A_ATTACK), g.getCurrentGameStatus().AttackVoteCandidates, round)
+}
+
+func (g *Game) collectVotes(request model.Request, agents []*model.Agent, candidates []model.Agent, round int) []model.Vote {
 	votes := make([]model.Vote, 0)
 	if request != model.R_VOTE && request != model.R_ATTACK {
 		return votes
 	}
-	for _, agent := range agents {
-		target, err := g.findTargetByRequest(agent, request)
+	abstain := g.setting.Vote.Abstain
+	if request == model.R_ATTACK {
+		abstain = g.setting.AttackVote.Abstain
+	}
+	responses := g.requestToAgents(agents, request)
+	for i, agent := range agents {
+		name, err := responses[i].text, responses[i].err
+		if err != nil {
+			continue
+		}
+		if abstain.Enable && name == model.V_ABSTAIN {
+			votes = append(votes, model.Vote{
+				Day:     g.getCurrentGameStatus().Day,
+				Agent:   *agent,
+				Round:   round,
+				Abstain: true,
+			})
+			g.recordVote(request, agent, nil, round)
+			slog.Info("棄権を受信しました", "id", g.id, "agent", agent.String())
+			continue
+		}
+		target, err := g.findTargetByName(agent, name)
 		if err != nil {
 			continue
 		}
@@ -31,6 +61,10 @@ func (g *Game) collectVotes(request model.Request, agents []*model.Agent) []mode
 			slog.Warn("投票対象が死亡しているため、投票を無視します", "id", g.id, "agent", agent.String(), "target", target.String())
 			continue
 		}
+		if len(candidates) > 0 && !slices.Contains(candidates, *target) {
+			slog.Warn("投票対象が決選投票の候補者ではないため、投票を無視します", "id", g.id, "agent", agent.String(), "target", target.String())
+			continue
+		}
 		if (request == model.R_VOTE && !g.config.Game.Vote.AllowSelfVote) || (request == model.R_ATTACK && !g.config.Game.AttackVote.AllowSelfVote) {
 			if agent.Idx == target.Idx {
 				slog.Warn("自己投票は許可されていないため、投票を無視します", "id", g.id, "agent", agent.String(), "target", target.String())
@@ -41,32 +75,86 @@ func (g *Game) collectVotes(request model.Request, agents []*model.Agent) []mode
 			Day:    g.getCurrentGameStatus().Day,
 			Agent:  *agent,
 			Target: *target,
+			Round:  round,
 		})
-		if g.gameLogger != nil {
-			if request == model.R_VOTE {
-				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,vote,%d,%d", g.currentDay, agent.Idx, target.Idx))
-			} else {
-				g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attackVote,%d,%d", g.currentDay, agent.Idx, target.Idx))
-			}
+		g.recordVote(request, agent, target, round)
+		slog.Info("投票を受信しました", "id", g.id, "agent", agent.String(), "target", target.String())
+	}
+	return votes
+
+}
+
+// recordVote は投票をゲームログとリアルタイムブロードキャスターに記録します
+// 棄権の場合はtargetにnilを指定します
+func (g *Game) recordVote(request model.Request, agent *model.Agent, target *model.Agent, round int) {
+	targetIdx := -1
+	if target != nil {
+		targetIdx = target.Idx
+	}
+	if g.gameLogger != nil {
+		if request == model.R_VOTE {
+			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,vote,%d,%d,%d", g.currentDay, agent.Idx, targetIdx, round))
+		} else {
+			g.gameLogger.AppendLog(g.id, fmt.Sprintf("%d,attackVote,%d,%d,%d", g.currentDay, agent.Idx, targetIdx, round))
+		}
+	}
+	if g.realtimeBroadcaster != nil {
+		packet := g.getRealtimeBroadcastPacket()
+		if request == model.R_VOTE {
+			packet.Event = "投票"
+		} else {
+			packet.Event = "襲撃投票"
 		}
+		packet.FromIdx = &agent.Idx
+		if target != nil {
+			packet.ToIdx = &target.Idx
+		} else {
+			message := "棄権"
+			packet.Message = &message
+		}
+		g.realtimeBroadcaster.Broadcast(packet)
+	}
+}
 
-		if g.realtimeBroadcaster != nil {
-			if request == model.R_VOTE {
-				packet := g.getRealtimeBroadcastPacket()
-				packet.Event = "投票"
-				packet.FromIdx = &agent.Idx
-				packet.ToIdx = &target.Idx
-				g.realtimeBroadcaster.Broadcast(packet)
-			} else {
-				packet := g.getRealtimeBroadcastPacket()
-				packet.Event = "襲撃投票"
-				packet.FromIdx = &agent.Idx
-				packet.ToIdx = &target.Idx
-				g.realtimeBroadcaster.Broadcast(packet)
-			}
+// resolveVotes は1回分の投票から最多票を得た候補者を求めます
+// 定足数に達しない場合は候補者なしとし、棄権が過半数で投票が取り消される場合はtrueを返します
+func (g *Game) resolveVotes(votes []model.Vote, voters int, abstain model.AbstainSetting, quorum float64, condition func(model.Vote) bool) ([]model.Agent, bool) {
+	abstains := 0
+	valid := 0
+	for _, vote := range votes {
+		if vote.Abstain {
+			abstains++
+		} else if condition(vote) {
+			valid++
 		}
-		slog.Info("投票を受信しました", "id", g.id, "agent", agent.String(), "target", target.String())
 	}
-	return votes
+	if abstain.MajorityCancels && abstains*2 > len(votes) {
+		slog.Info("棄権が過半数であるため、投票を取り消します", "id", g.id, "abstains", abstains, "votes", len(votes))
+		return []model.Agent{}, true
+	}
+	if quorum > 0 {
+		counted := valid
+		if abstain.CountInQuorum {
+			counted += abstains
+		}
+		if float64(counted) < quorum*float64(voters) {
+			slog.Info("定足数に達していないため、投票を無効にします", "id", g.id, "counted", counted, "voters", voters)
+			return []model.Agent{}, false
+		}
+	}
+	return util.GetCandidates(votes, func(vote model.Vote) bool {
+		return !vote.Abstain && condition(vote)
+	}), false
+}
 
+func (g *Game) breakTie(tieBreak model.TieBreak, candidates []model.Agent) []model.Agent {
+	switch tieBreak {
+	case model.TB_NONE:
+		slog.Info("同票のため、対象を決定しません", "id", g.id, "candidates", len(candidates))
+		return []model.Agent{}
+	case model.TB_ALL:
+		slog.Info("同票のため、候補者全員を対象にします", "id", g.id, "candidates", len(candidates))
+		return candidates
+	}
+	return []model.Agent{util.SelectRandomAgent(g.rng, candidates)}
 }
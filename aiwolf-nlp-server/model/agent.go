@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -20,7 +21,10 @@ type Agent struct {
 	ProfileDescription *string
 	Role               Role
 	Connection         *websocket.Conn
-	HasError           bool
+	Hello              *Hello
+	hasError           *atomic.Bool
+	inbound            *InboundRouter
+	outbound           *OutboundQueue
 }
 
 func NewAgent(idx int, role Role, conn Connection) *Agent {
@@ -33,7 +37,8 @@ func NewAgent(idx int, role Role, conn Connection) *Agent {
 		ProfileDescription: nil,
 		Role:               role,
 		Connection:         conn.Conn,
-		HasError:           false,
+		Hello:              conn.Hello,
+		hasError:           &atomic.Bool{},
 	}
 	slog.Info("エージェントを作成しました", "idx", agent.Idx, "agent", agent.String(), "role", agent.Role, "connection", agent.Connection.RemoteAddr())
 	return agent
@@ -57,115 +62,220 @@ func NewAgentWithProfile(idx int, role Role, conn Connection, profile Profile, e
 		ProfileDescription: &description,
 		Role:               role,
 		Connection:         conn.Conn,
-		HasError:           false,
+		Hello:              conn.Hello,
+		hasError:           &atomic.Bool{},
 	}
 	slog.Info("エージェントを作成しました", "idx", agent.Idx, "agent", agent.String(), "profile", agent.ProfileDescription, "role", agent.Role, "connection", agent.Connection.RemoteAddr())
 	return agent
 }
 
-func (a *Agent) SendPacket(packet Packet, actionTimeout, responseTimeout, acceptableTimeout time.Duration) (string, error) {
-	if a.HasError {
+func (a *Agent) SendPacket(packet Packet, actionTimeout, responseTimeout, acceptableTimeout time.Duration) (string, *Response, error) {
+	if a.HasError() {
 		slog.Error("エージェントにエラーが発生しているため、リクエストを送信できません", "agent", a.String())
-		return "", errors.New("エージェントにエラーが発生しているため、リクエストを送信できません")
+		return "", nil, errors.New("エージェントにエラーが発生しているため、リクエストを送信できません")
 	}
+	requestID := newRequestID()
+	packet.RequestID = &requestID
 	req, err := json.Marshal(packet)
 	if err != nil {
 		slog.Error("パケットの作成に失敗しました", "error", err)
-		a.HasError = true
-		return "", err
+		a.MarkError()
+		return "", nil, err
 	}
-	err = a.Connection.WriteMessage(websocket.TextMessage, req)
+	// 送信直後のレスポンスを取りこぼさないように、送信前に応答待ちを登録する
+	var frames <-chan inboundFrame
+	if packet.Request.RequireResponse {
+		frames = a.expect(requestID)
+		defer a.inbound.release()
+	}
+	err = a.write(req)
 	if err != nil {
 		slog.Error("パケットの送信に失敗しました", "error", err)
-		a.HasError = true
-		return "", err
+		a.MarkError()
+		return "", nil, err
 	}
 	slog.Info("パケットを送信しました", "agent", a.String(), "packet", packet)
 	if packet.Request.RequireResponse {
-		responseChan := make(chan []byte)
-		errChan := make(chan error)
-		go func() {
-			_, res, err := a.Connection.ReadMessage()
-			if err != nil {
-				errChan <- err
-				return
-			}
-			responseChan <- res
-		}()
 		select {
-		case res := <-responseChan:
-			response := strings.ReplaceAll(string(res), "\n", "")
-			slog.Info("レスポンスを受信しました", "agent", a.String(), "response", response)
-			return response, nil
-		case err := <-errChan:
-			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
-				slog.Error("接続が閉じられました", "error", err)
-				a.HasError = true
-				return "", err
+		case frame := <-frames:
+			if frame.err == nil {
+				response := strings.ReplaceAll(frame.text, "\n", "")
+				slog.Info("レスポンスを受信しました", "agent", a.String(), "response", response)
+				return response, frame.response, nil
 			}
-			slog.Warn("レスポンスの受信に失敗したため、NAMEリクエストを送信します", "agent", a.String(), "error", err)
+			// 受信に失敗した接続からは以降も受信できないため、NAMEリクエストを送信しない
+			slog.Error("レスポンスの受信に失敗しました", "agent", a.String(), "error", frame.err)
+			a.MarkError()
+			return "", nil, frame.err
 		case <-time.After(actionTimeout + acceptableTimeout):
 			slog.Warn("レスポンスの受信がタイムアウトしたため、NAMEリクエストを送信します", "agent", a.String())
 		}
-		nameReq, err := json.Marshal(Packet{Request: &R_NAME})
+		// 遅れて届いた元のリクエストへのレスポンスを破棄するため、NAMEリクエストのリクエストIDで応答を待つ
+		nameRequestID := newRequestID()
+		a.inbound.rebind(nameRequestID)
+		nameReq, err := json.Marshal(Packet{RequestID: &nameRequestID, Request: &R_NAME})
 		if err != nil {
 			slog.Error("NAMEパケットの作成に失敗しました", "error", err)
-			a.HasError = true
-			return "", err
+			a.MarkError()
+			return "", nil, err
 		}
-		err = a.Connection.WriteMessage(websocket.TextMessage, nameReq)
+		err = a.write(nameReq)
 		if err != nil {
 			slog.Error("NAMEパケットの送信に失敗しました", "error", err)
-			a.HasError = true
-			return "", err
+			a.MarkError()
+			return "", nil, err
 		}
 		slog.Info("NAMEパケットを送信しました", "agent", a.String())
 		select {
-		case res := <-responseChan:
-			if strings.TrimRight(string(res), "\n") == a.OriginalName {
-				slog.Info("NAMEリクエストのレスポンスを受信しました", "agent", a.String(), "response", string(res))
-				return "", errors.New("リクエストのレスポンス受信がタイムアウトしました")
+		case frame := <-frames:
+			if frame.err != nil {
+				slog.Error("NAMEリクエストのレスポンス受信に失敗しました", "agent", a.String(), "error", frame.err)
+				a.MarkError()
+				return "", nil, frame.err
+			}
+			// 接続時に自己紹介を送信したエージェントは、NAMEリクエストに自己紹介で応答する
+			name := frame.text
+			if hello := parseHello([]byte(frame.text)); hello != nil {
+				name = hello.Name
+			}
+			if name == a.OriginalName {
+				slog.Info("NAMEリクエストのレスポンスを受信しました", "agent", a.String(), "response", frame.text)
+				return "", nil, errors.New("リクエストのレスポンス受信がタイムアウトしました")
 			} else {
-				slog.Error("不正なNAMEリクエストのレスポンスを受信しました", "agent", a.String(), "response", string(res))
-				a.HasError = true
-				return "", errors.New("不正なNAMEリクエストのレスポンスを受信しました")
+				slog.Error("不正なNAMEリクエストのレスポンスを受信しました", "agent", a.String(), "response", frame.text)
+				a.MarkError()
+				return "", nil, errors.New("不正なNAMEリクエストのレスポンスを受信しました")
 			}
-		case err := <-errChan:
-			slog.Error("NAMEリクエストのレスポンス受信に失敗しました", "agent", a.String(), "error", err)
-			a.HasError = true
-			return "", err
 		case <-time.After(responseTimeout):
 			slog.Error("NAMEリクエストのレスポンス受信がタイムアウトしました", "agent", a.String())
-			a.HasError = true
-			return "", errors.New("NAMEリクエストのレスポンス受信がタイムアウトしました")
+			a.MarkError()
+			return "", nil, errors.New("NAMEリクエストのレスポンス受信がタイムアウトしました")
 		}
 	}
-	return "", nil
+	return "", nil, nil
 }
 
 func (a Agent) Close() {
+	if a.inbound != nil {
+		a.inbound.Close()
+	}
+	if a.outbound != nil {
+		a.outbound.Close()
+	}
 	a.Connection.Close()
 	slog.Info("エージェントをクローズしました", "agent", a.String())
 }
 
+// HasError はエージェントにエラーが発生しているかどうかを返します
+// エラーは読み取りgoroutineや書き込みgoroutineからも記録されるため、アトミックに読み書きします
+func (a *Agent) HasError() bool {
+	return a.hasError != nil && a.hasError.Load()
+}
+
+// MarkError はエージェントにエラーが発生したことを記録します
+func (a *Agent) MarkError() {
+	if a.hasError == nil {
+		return
+	}
+	a.hasError.Store(true)
+}
+
 // SendNonBlocking はレスポンスを待たずにパケットを送信します（リアルタイム通信用）
 func (a *Agent) SendNonBlocking(packet Packet) error {
-	if a.HasError {
+	if a.HasError() {
 		return errors.New("エージェントにエラーが発生しているため、送信できません")
 	}
+	requestID := newRequestID()
+	packet.RequestID = &requestID
 	req, err := json.Marshal(packet)
 	if err != nil {
-		a.HasError = true
+		a.MarkError()
 		return err
 	}
+	if a.outbound != nil {
+		return a.outbound.Enqueue(req)
+	}
 	err = a.Connection.WriteMessage(websocket.TextMessage, req)
 	if err != nil {
-		a.HasError = true
+		a.MarkError()
 		return err
 	}
 	return nil
 }
 
+// HasCapability は接続時のネゴシエーションで機能が有効になったかどうかを返します
+// 自己紹介を送信しなかったエージェントは、いずれの機能も有効になりません
+func (a *Agent) HasCapability(capability Capability) bool {
+	return a.Hello != nil && a.Hello.HasCapability(capability)
+}
+
+// StartInbound は受信の読み取りgoroutineを開始します
+// 開始後は全ての受信が読み取りgoroutineを経由し、応答待ちのリクエストまたはリアルタイム通信のリスナーに振り分けられます
+// 構造化されたレスポンスの理由やトークン数などは、ネゴシエーションで structured_response が有効になった場合のみ受け付けます
+func (a *Agent) StartInbound() {
+	a.inbound = newInboundRouter(a.Connection, a.HasCapability(CAP_STRUCTURED_RESPONSE), func() {
+		a.MarkError()
+	})
+}
+
+// expect は応答待ちのリクエストを登録します
+// 読み取りgoroutineを開始していない場合は開始します
+func (a *Agent) expect(requestID string) <-chan inboundFrame {
+	if a.inbound == nil {
+		a.StartInbound()
+	}
+	return a.inbound.expect(requestID)
+}
+
+// Listen はリアルタイム通信で受信した発言を受け取るリスナーを登録します
+func (a *Agent) Listen(listener func(text string, response *Response)) {
+	if a.inbound == nil {
+		a.StartInbound()
+	}
+	a.inbound.Listen(listener)
+}
+
+// Unlisten はリアルタイム通信のリスナーの登録を解除します
+func (a *Agent) Unlisten() {
+	if a.inbound != nil {
+		a.inbound.Unlisten()
+	}
+}
+
+// Drain はフェーズ終了後に遅れて届いたメッセージが途切れるまで待ち、破棄したメッセージ数を返します
+func (a *Agent) Drain(quiet, timeout time.Duration) int {
+	if a.inbound == nil {
+		return 0
+	}
+	return a.inbound.Drain(quiet, timeout)
+}
+
+// StartOutbound は送信キューと書き込みgoroutineを開始します
+// 開始後は全ての送信が送信キューを経由します
+func (a *Agent) StartOutbound(setting OutboundSetting) {
+	a.outbound = newOutboundQueue(a.Connection, setting, func() {
+		a.MarkError()
+	})
+}
+
+// OutboundStats は送信キューの統計情報を返します
+// 送信キューを開始していない場合は nil を返します
+func (a *Agent) OutboundStats() *OutboundStats {
+	if a.outbound == nil {
+		return nil
+	}
+	stats := a.outbound.Stats()
+	return &stats
+}
+
+// write はパケットを書き込み、書き込みが完了するまで待ちます
+func (a *Agent) write(data []byte) error {
+	if a.outbound != nil {
+		return a.outbound.Send(data)
+	}
+	return a.Connection.WriteMessage(websocket.TextMessage, data)
+}
+
 func (a Agent) String() string {
 	return a.GameName
 }
@@ -34,28 +34,84 @@ type ServerConfig struct {
 		Response   time.Duration `yaml:"response"`
 		Acceptable time.Duration `yaml:"acceptable"`
 	} `yaml:"timeout"`
-	MaxContinueErrorRatio float64 `yaml:"max_continue_error_ratio"`
-	ManualStart           bool    `yaml:"manual_start"`
+	Outbound              OutboundConfig  `yaml:"outbound"`
+	Handshake             HandshakeConfig `yaml:"handshake"`
+	MaxContinueErrorRatio float64         `yaml:"max_continue_error_ratio"`
+	ManualStart           bool            `yaml:"manual_start"`
+}
+
+type HandshakeConfig struct {
+	RequireHello bool     `yaml:"require_hello"`
+	Languages    []string `yaml:"languages"`
+}
+
+type OutboundConfig struct {
+	QueueSize    int           `yaml:"queue_size"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	FullPolicy   string        `yaml:"full_policy"`
 }
 
 type GameConfig struct {
-	AgentCount     int        `yaml:"agent_count"`
-	MaxDay         int        `yaml:"max_day"`
-	VoteVisibility bool       `yaml:"vote_visibility"`
-	Talk           TalkConfig     `yaml:"talk"`
-	Whisper        TalkConfig     `yaml:"whisper"`
-	Realtime       RealtimeConfig `yaml:"realtime"`
+	AgentCount     int               `yaml:"agent_count"`
+	MaxDay         int               `yaml:"max_day"`
+	MaxDayOutcome  string            `yaml:"max_day_outcome"`
+	Seed           *int64            `yaml:"seed"`
+	VoteVisibility bool              `yaml:"vote_visibility"`
+	Talk           TalkConfig        `yaml:"talk"`
+	Whisper        TalkConfig        `yaml:"whisper"`
+	MasonTalk      *TalkConfig       `yaml:"mason_talk"`
+	LastWords      LastWordsConfig   `yaml:"last_words"`
+	Guard          GuardConfig       `yaml:"guard"`
+	NightAction    NightActionConfig `yaml:"night_action"`
+	Spectator      SpectatorConfig   `yaml:"spectator"`
+	Realtime       RealtimeConfig    `yaml:"realtime"`
 	Vote           struct {
-		MaxCount      int  `yaml:"max_count"`
-		AllowSelfVote bool `yaml:"allow_self_vote"`
+		MaxCount      int           `yaml:"max_count"`
+		AllowSelfVote bool          `yaml:"allow_self_vote"`
+		Runoff        bool          `yaml:"runoff"`
+		TieBreak      string        `yaml:"tie_break"`
+		Abstain       AbstainConfig `yaml:"abstain"`
+		Quorum        float64       `yaml:"quorum"`
 	} `yaml:"vote"`
 	AttackVote struct {
-		MaxCount      int  `yaml:"max_count"`
-		AllowSelfVote bool `yaml:"allow_self_vote"`
-		AllowNoTarget bool `yaml:"allow_no_target"`
+		MaxCount      int           `yaml:"max_count"`
+		AllowSelfVote bool          `yaml:"allow_self_vote"`
+		AllowNoTarget bool          `yaml:"allow_no_target"`
+		Runoff        bool          `yaml:"runoff"`
+		TieBreak      string        `yaml:"tie_break"`
+		Abstain       AbstainConfig `yaml:"abstain"`
+		Quorum        float64       `yaml:"quorum"`
 	} `yaml:"attack_vote"`
 }
 
+type GuardConfig struct {
+	AllowSelfGuard        bool `yaml:"allow_self_guard"`
+	BanConsecutiveGuard   bool `yaml:"ban_consecutive_guard"`
+	AnnounceGuardedAttack bool `yaml:"announce_guarded_attack"`
+}
+
+type NightActionConfig struct {
+	Simultaneous    bool     `yaml:"simultaneous"`
+	ResolutionOrder []string `yaml:"resolution_order"`
+}
+
+type SpectatorConfig struct {
+	Enable        bool        `yaml:"enable"`
+	RevealRoles   bool        `yaml:"reveal_roles"`
+	GraveyardTalk *TalkConfig `yaml:"graveyard_talk"`
+}
+
+type AbstainConfig struct {
+	Enable          bool `yaml:"enable"`
+	CountInQuorum   bool `yaml:"count_in_quorum"`
+	MajorityCancels bool `yaml:"majority_cancels"`
+}
+
+type LastWordsConfig struct {
+	Enable    bool `yaml:"enable"`
+	MaxLength int  `yaml:"max_length"`
+}
+
 type TalkConfig struct {
 	MaxCount struct {
 		PerAgent int `yaml:"per_agent"`
@@ -69,14 +125,34 @@ type TalkConfig struct {
 		PerAgent      int  `yaml:"per_agent"`
 		BaseLength    int  `yaml:"base_length"`
 	} `yaml:"max_length"`
-	MaxSkip int `yaml:"max_skip"`
+	MaxSkip int    `yaml:"max_skip"`
+	Order   string `yaml:"order"`
+	Bid     struct {
+		MaxConsecutive int `yaml:"max_consecutive"`
+	} `yaml:"bid"`
 }
 
 type RealtimeConfig struct {
-	Enable         bool          `yaml:"enable"`
-	PhaseTimeout   time.Duration `yaml:"phase_timeout"`
-	SilenceTimeout time.Duration `yaml:"silence_timeout"`
-	RateLimit      time.Duration `yaml:"rate_limit"`
+	Enable               bool            `yaml:"enable"`
+	PhaseTimeout         time.Duration   `yaml:"phase_timeout"`
+	SilenceTimeout       time.Duration   `yaml:"silence_timeout"`
+	RateLimit            time.Duration   `yaml:"rate_limit"`
+	Moderator            ModeratorConfig `yaml:"moderator"`
+	Adaptive             AdaptiveConfig  `yaml:"adaptive"`
+	RemainingTimeNotices []time.Duration `yaml:"remaining_time_notices"`
+}
+
+type AdaptiveConfig struct {
+	Enable       bool          `yaml:"enable"`
+	ActiveWindow time.Duration `yaml:"active_window"`
+	Extension    time.Duration `yaml:"extension"`
+	MaxDuration  time.Duration `yaml:"max_duration"`
+}
+
+type ModeratorConfig struct {
+	Enable     bool          `yaml:"enable"`
+	NudgeAfter time.Duration `yaml:"nudge_after"`
+	MaxNudges  int           `yaml:"max_nudges"`
 }
 
 type AgentSpawnerConfig struct {
@@ -87,16 +163,52 @@ type AgentSpawnerConfig struct {
 }
 
 type LogicConfig struct {
-	DayPhases   []Phase                `yaml:"day_phases"`
-	NightPhases []Phase                `yaml:"night_phases"`
-	Roles       map[int]map[string]int `yaml:"roles"`
+	DayPhases       []Phase                `yaml:"day_phases"`
+	NightPhases     []Phase                `yaml:"night_phases"`
+	Roles           map[int]map[string]int `yaml:"roles"`
+	RoleDefinitions []RoleDefinitionConfig `yaml:"role_definitions"`
+	Visibility      VisibilityConfig       `yaml:"visibility"`
+}
+
+type VisibilityConfig struct {
+	VoteList       *[]string           `yaml:"vote_list"`
+	AttackVoteList *[]string           `yaml:"attack_vote_list"`
+	MediumResult   *[]string           `yaml:"medium_result"`
+	DivineResult   *[]string           `yaml:"divine_result"`
+	Whisper        *[]string           `yaml:"whisper"`
+	MasonTalk      *[]string           `yaml:"mason_talk"`
+	GuardedAgent   *[]string           `yaml:"guarded_agent"`
+	ExecutedRole   *[]string           `yaml:"executed_role"`
+	RoleMap        map[string][]string `yaml:"role_map"`
+}
+
+type RoleDefinitionConfig struct {
+	Name           string   `yaml:"name"`
+	Team           string   `yaml:"team"`
+	Species        string   `yaml:"species"`
+	Abilities      []string `yaml:"abilities"`
+	Knows          []string `yaml:"knows"`
+	Channels       []string `yaml:"channels"`
+	ResistAttack   bool     `yaml:"resist_attack"`
+	CursedByDivine bool     `yaml:"cursed_by_divine"`
 }
 
 type Phase struct {
-	Name      string   `yaml:"name"`
-	Actions   []string `yaml:"actions"`
-	OnlyDay   *int     `yaml:"only_day,omitempty"`
-	ExceptDay *int     `yaml:"except_day,omitempty"`
+	Name          string    `yaml:"name"`
+	Actions       []string  `yaml:"actions"`
+	OnlyDay       *int      `yaml:"only_day,omitempty"`
+	ExceptDay     *int      `yaml:"except_day,omitempty"`
+	DayRange      *DayRange `yaml:"day_range,omitempty"`
+	EveryNDays    *int      `yaml:"every_n_days,omitempty"`
+	MinAlive      *int      `yaml:"min_alive,omitempty"`
+	MaxAlive      *int      `yaml:"max_alive,omitempty"`
+	RoleAlive     *string   `yaml:"role_alive,omitempty"`
+	SkipIfNoDeath bool      `yaml:"skip_if_no_death,omitempty"`
+}
+
+type DayRange struct {
+	Min *int `yaml:"min,omitempty"`
+	Max *int `yaml:"max,omitempty"`
 }
 
 type MatchingConfig struct {
@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
@@ -14,11 +15,14 @@ type Connection struct {
 	OriginalName string
 	Conn         *websocket.Conn
 	Header       *http.Header
+	Hello        *Hello
 }
 
 func NewConnection(conn *websocket.Conn, header *http.Header) (*Connection, error) {
+	requestID := newRequestID()
 	req, err := json.Marshal(Packet{
-		Request: &R_NAME,
+		RequestID: &requestID,
+		Request:   &R_NAME,
 	})
 	if err != nil {
 		slog.Error("NAMEパケットの作成に失敗しました", "error", err)
@@ -35,14 +39,61 @@ func NewConnection(conn *websocket.Conn, header *http.Header) (*Connection, erro
 		slog.Error("NAMEリクエストの受信に失敗しました", "error", err)
 		return nil, err
 	}
-	originalName := strings.TrimRight(string(res), "\n")
+	// 自己紹介の場合は、名前とチーム名を自己紹介から取得する
+	hello := parseHello(res)
+	var originalName string
+	if hello != nil {
+		originalName = hello.Name
+	} else {
+		_, originalName = parseReply(res)
+	}
 	teamName := strings.TrimRight(originalName, "1234567890")
+	if hello != nil && hello.Team != nil {
+		teamName = *hello.Team
+	}
 	connection := Connection{
 		TeamName:     teamName,
 		OriginalName: originalName,
 		Conn:         conn,
 		Header:       header,
+		Hello:        hello,
 	}
 	slog.Info("クライアントが接続しました", "team_name", connection.TeamName, "original_name", connection.OriginalName, "remote_addr", conn.RemoteAddr().String())
 	return &connection, nil
 }
+
+// Negotiate はエージェントの自己紹介をサーバの設定と照合し、自己紹介を送信したエージェントに HELLO リクエストで結果を返します
+// ネゴシエーション後の Hello は、双方が対応している機能のみを含む自己紹介に置き換えます
+// 互換性のないエージェントの場合は、理由を付けて接続を切断し、エラーを返します
+func (c *Connection) Negotiate(config Config) error {
+	hello, err := NegotiateHello(c.Hello, config)
+	if err != nil {
+		message := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error())
+		c.Conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+		c.Conn.Close()
+		return err
+	}
+	c.Hello = hello
+	if hello == nil {
+		return nil
+	}
+	requestID := newRequestID()
+	req, err := json.Marshal(Packet{
+		RequestID: &requestID,
+		Request:   &R_HELLO,
+		Hello:     hello,
+	})
+	if err != nil {
+		slog.Error("HELLOパケットの作成に失敗しました", "error", err)
+		c.Conn.Close()
+		return err
+	}
+	err = c.Conn.WriteMessage(websocket.TextMessage, req)
+	if err != nil {
+		slog.Error("HELLOパケットの送信に失敗しました", "error", err)
+		c.Conn.Close()
+		return err
+	}
+	slog.Info("HELLOパケットを送信しました", "team_name", c.TeamName, "protocol", hello.Protocol, "capabilities", hello.Capabilities)
+	return nil
+}
@@ -1,7 +1,50 @@
 package model
 
+import "encoding/json"
+
 type Guard struct {
 	Day    int   `json:"day"`
 	Agent  Agent `json:"agent"`
 	Target Agent `json:"target"`
 }
+
+type GuardErrorReason string
+
+const (
+	GE_NOT_FOUND         GuardErrorReason = "NOT_FOUND"
+	GE_DEAD_TARGET       GuardErrorReason = "DEAD_TARGET"
+	GE_SELF_GUARD        GuardErrorReason = "SELF_GUARD"
+	GE_CONSECUTIVE_GUARD GuardErrorReason = "CONSECUTIVE_GUARD"
+)
+
+type GuardError struct {
+	Day    int              `json:"day"`
+	Agent  Agent            `json:"agent"`
+	Target *Agent           `json:"target"`
+	Reason GuardErrorReason `json:"reason"`
+}
+
+func (e GuardError) Error() string {
+	switch e.Reason {
+	case GE_NOT_FOUND:
+		return "護衛対象が見つかりません"
+	case GE_DEAD_TARGET:
+		return "護衛対象が死亡しています"
+	case GE_SELF_GUARD:
+		return "護衛対象が自分自身です"
+	case GE_CONSECUTIVE_GUARD:
+		return "護衛対象が前日と同じです"
+	}
+	return "護衛対象が不正です"
+}
+
+func (e GuardError) MarshalJSON() ([]byte, error) {
+	type Alias GuardError
+	return json.Marshal(&struct {
+		*Alias
+		Message string `json:"message"`
+	}{
+		Alias:   (*Alias)(&e),
+		Message: e.Error(),
+	})
+}
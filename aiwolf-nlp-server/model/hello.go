@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ProtocolVersion はサーバが対応するプロトコルのバージョンです
+const ProtocolVersion = 1
+
+type Capability string
+
+const (
+	CAP_REALTIME            Capability = "realtime"
+	CAP_STRUCTURED_RESPONSE Capability = "structured_response"
+	CAP_COMPRESSION         Capability = "compression"
+)
+
+// serverCapabilities はサーバが対応する機能です
+var serverCapabilities = []Capability{CAP_REALTIME, CAP_STRUCTURED_RESPONSE}
+
+func (c Capability) String() string {
+	return string(c)
+}
+
+// Hello は接続時にNAMEリクエストへのレスポンスとしてエージェントが送信する自己紹介です
+// サーバはネゴシエーションの結果を同じ形式で HELLO リクエストとして返します
+type Hello struct {
+	Protocol     int          `json:"protocol"`
+	Capabilities []Capability `json:"capabilities"`
+	Language     *string      `json:"language,omitempty"`
+	Team         *string      `json:"team,omitempty"`
+	Name         string       `json:"name"`
+}
+
+// parseHello はNAMEリクエストへのレスポンスが自己紹介の場合に、自己紹介を返します
+// 名前のみのレスポンスの場合は nil を返します
+func parseHello(data []byte) *Hello {
+	var hello Hello
+	if err := json.Unmarshal(data, &hello); err != nil || hello.Name == "" {
+		return nil
+	}
+	return &hello
+}
+
+// HasCapability はエージェントが機能に対応しているかどうかを返します
+func (h Hello) HasCapability(capability Capability) bool {
+	return slices.Contains(h.Capabilities, capability)
+}
+
+// NegotiateHello はエージェントの自己紹介をサーバの設定と照合し、サーバの自己紹介を返します
+// 互換性のないエージェントの場合はエラーを返します
+// 自己紹介を送信しないエージェントは、require_hello が無効な場合のみ従来のプロトコルのエージェントとして受け入れ、nil を返します
+func NegotiateHello(hello *Hello, config Config) (*Hello, error) {
+	if hello == nil {
+		if config.Server.Handshake.RequireHello {
+			return nil, errors.New("自己紹介が必要です")
+		}
+		return nil, nil
+	}
+	if hello.Protocol <= 0 || hello.Protocol > ProtocolVersion {
+		return nil, fmt.Errorf("対応していないプロトコルのバージョンです: %d", hello.Protocol)
+	}
+	if config.Game.Realtime.Enable && !hello.HasCapability(CAP_REALTIME) {
+		return nil, errors.New("リアルタイム通信に対応していません")
+	}
+	languages := config.Server.Handshake.Languages
+	if len(languages) > 0 && hello.Language != nil && !slices.Contains(languages, *hello.Language) {
+		return nil, errors.New("対応していない言語です: " + *hello.Language)
+	}
+
+	// 双方が対応している機能のみを有効にする
+	capabilities := make([]Capability, 0)
+	for _, capability := range hello.Capabilities {
+		if slices.Contains(serverCapabilities, capability) && !slices.Contains(capabilities, capability) {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	return &Hello{
+		Protocol:     ProtocolVersion,
+		Capabilities: capabilities,
+		Language:     hello.Language,
+		Team:         hello.Team,
+		Name:         hello.Name,
+	}, nil
+}
@@ -0,0 +1,240 @@
+package model
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 応答待ちのリクエストに保持できる最大フレーム数
+const pendingFrameBuffer = 8
+
+// 遅れて届いたレスポンスを判別するために保持するリクエストIDの最大数
+const expectedRequestIDLimit = 64
+
+type inboundFrame struct {
+	text     string
+	response *Response
+	err      error
+}
+
+// InboundRouter はエージェントからの受信を1つの読み取りgoroutineで行い、受信したフレームを振り分けます
+// 応答待ちのリクエストがある場合はリクエストに、リアルタイム通信のリスナーがある場合はリスナーに渡し、どちらもない場合は破棄します
+type InboundRouter struct {
+	conn          *websocket.Conn
+	structured    bool
+	onError       func()
+	mu            sync.Mutex
+	pending       chan inboundFrame
+	pendingID     string
+	expected      map[string]struct{}
+	expectedOrder []string
+	listener      func(text string, response *Response)
+	discarded     int
+	discardNotify chan struct{}
+	failed        error
+	stopped       bool
+	closed        chan struct{}
+}
+
+func newInboundRouter(conn *websocket.Conn, structured bool, onError func()) *InboundRouter {
+	r := &InboundRouter{
+		conn:          conn,
+		structured:    structured,
+		onError:       onError,
+		expected:      make(map[string]struct{}),
+		discardNotify: make(chan struct{}, 1),
+		closed:        make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// expect は応答待ちのリクエストを登録し、以降に受信したフレームを受け取るチャネルを返します
+// リクエストの送信前に登録することで、送信直後に届いたレスポンスも取りこぼしません
+// リクエストIDが付いたレスポンスは、requestID と一致する場合のみ受け取ります
+func (r *InboundRouter) expect(requestID string) <-chan inboundFrame {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch := make(chan inboundFrame, pendingFrameBuffer)
+	if r.failed != nil {
+		ch <- inboundFrame{err: r.failed}
+		return ch
+	}
+	r.pending = ch
+	r.pendingID = requestID
+	r.remember(requestID)
+	return ch
+}
+
+// rebind は応答待ちのリクエストのリクエストIDを変更します
+// 変更後は、変更前のリクエストIDが付いたレスポンスを破棄します
+func (r *InboundRouter) rebind(requestID string) {
+	r.mu.Lock()
+	r.pendingID = requestID
+	r.remember(requestID)
+	r.mu.Unlock()
+}
+
+// remember は応答待ちにしたリクエストIDを記録します
+// 記録するリクエストIDは直近の expectedRequestIDLimit 件までとし、古いものから削除します
+// 呼び出し元で mu をロックしている必要があります
+func (r *InboundRouter) remember(requestID string) {
+	if requestID == "" {
+		return
+	}
+	if _, exists := r.expected[requestID]; exists {
+		return
+	}
+	r.expected[requestID] = struct{}{}
+	r.expectedOrder = append(r.expectedOrder, requestID)
+	if len(r.expectedOrder) > expectedRequestIDLimit {
+		delete(r.expected, r.expectedOrder[0])
+		r.expectedOrder = r.expectedOrder[1:]
+	}
+}
+
+// release は応答待ちのリクエストの登録を解除します
+// 解除後に受信したフレームは破棄されるため、タイムアウト後に遅れて届いたレスポンスが次のリクエストに混ざりません
+func (r *InboundRouter) release() {
+	r.mu.Lock()
+	r.pending = nil
+	r.mu.Unlock()
+}
+
+// Listen はリアルタイム通信のリスナーを登録します
+// リスナーは読み取りgoroutineから呼び出されます
+func (r *InboundRouter) Listen(listener func(text string, response *Response)) {
+	r.mu.Lock()
+	r.listener = listener
+	r.mu.Unlock()
+}
+
+// Unlisten はリアルタイム通信のリスナーの登録を解除します
+func (r *InboundRouter) Unlisten() {
+	r.mu.Lock()
+	r.listener = nil
+	r.mu.Unlock()
+}
+
+// Drain は破棄されるフレームが quiet の間届かなくなるか timeout が経過するまで待ち、その間に破棄したフレーム数を返します
+func (r *InboundRouter) Drain(quiet, timeout time.Duration) int {
+	r.mu.Lock()
+	start := r.discarded
+	r.mu.Unlock()
+
+	deadline := time.After(timeout)
+	quietTimer := time.NewTimer(quiet)
+	defer quietTimer.Stop()
+wait:
+	for {
+		select {
+		case <-r.discardNotify:
+			quietTimer.Reset(quiet)
+		case <-quietTimer.C:
+			break wait
+		case <-deadline:
+			break wait
+		case <-r.closed:
+			break wait
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.discarded - start
+}
+
+func (r *InboundRouter) run() {
+	defer close(r.closed)
+	for {
+		_, data, err := r.conn.ReadMessage()
+		if err != nil {
+			r.mu.Lock()
+			r.failed = err
+			if r.pending != nil {
+				select {
+				case r.pending <- inboundFrame{err: err}:
+				default:
+				}
+			}
+			stopped := r.stopped
+			r.mu.Unlock()
+			// Close による切断はエラーとして扱わない
+			if stopped {
+				return
+			}
+			slog.Warn("受信に失敗したため、読み取りを終了します", "remote_addr", r.conn.RemoteAddr().String(), "error", err)
+			r.onError()
+			return
+		}
+		r.route(data)
+	}
+}
+
+func (r *InboundRouter) route(data []byte) {
+	response, text := parseReply(data)
+	// structured_response をネゴシエーションしていないエージェントからは、リクエストIDとテキストのみ受け付ける
+	if response != nil && !r.structured {
+		response.Rationale = nil
+		response.Usage = nil
+		response.Model = nil
+	}
+	var requestID string
+	if response != nil && response.RequestID != nil {
+		requestID = *response.RequestID
+	}
+	hasRequestID := requestID != ""
+	r.mu.Lock()
+	if r.pending != nil {
+		if hasRequestID && requestID != r.pendingID {
+			expectedID := r.pendingID
+			r.mu.Unlock()
+			slog.Warn("リクエストIDが一致しないため、レスポンスを破棄しました", "remote_addr", r.conn.RemoteAddr().String(), "request_id", requestID, "expected", expectedID, "text", text)
+			r.discard()
+			return
+		}
+		select {
+		case r.pending <- inboundFrame{text: text, response: response}:
+			r.mu.Unlock()
+			return
+		default:
+		}
+	} else if r.listener != nil {
+		// 応答待ちだったリクエストへの遅れたレスポンスは、リアルタイム通信の発言として扱わない
+		if _, exists := r.expected[requestID]; hasRequestID && exists {
+			r.mu.Unlock()
+			slog.Warn("タイムアウト後に届いたレスポンスを破棄しました", "remote_addr", r.conn.RemoteAddr().String(), "request_id", requestID, "text", text)
+			r.discard()
+			return
+		}
+		listener := r.listener
+		r.mu.Unlock()
+		listener(text, response)
+		return
+	}
+	r.mu.Unlock()
+
+	slog.Info("staleメッセージを破棄しました", "remote_addr", r.conn.RemoteAddr().String(), "request_id", requestID, "text", text)
+	r.discard()
+}
+
+func (r *InboundRouter) discard() {
+	r.mu.Lock()
+	r.discarded++
+	r.mu.Unlock()
+	select {
+	case r.discardNotify <- struct{}{}:
+	default:
+	}
+}
+
+// Close は接続の切断を受信エラーとして扱わないようにします
+// 読み取りgoroutineは接続の切断により終了します
+func (r *InboundRouter) Close() {
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+}
@@ -0,0 +1,270 @@
+package model
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type QueuePolicy string
+
+const (
+	QP_DROP_OLDEST QueuePolicy = "drop_oldest"
+	QP_MARK_ERROR  QueuePolicy = "mark_error"
+	QP_DISCONNECT  QueuePolicy = "disconnect"
+)
+
+func QueuePolicyFromString(s string) (QueuePolicy, error) {
+	switch s {
+	case "drop_oldest":
+		return QP_DROP_OLDEST, nil
+	case "mark_error":
+		return QP_MARK_ERROR, nil
+	case "disconnect":
+		return QP_DISCONNECT, nil
+	}
+	return "", errors.New("不明なキューのポリシーです: " + s)
+}
+
+func (q QueuePolicy) String() string {
+	return string(q)
+}
+
+// 送信キューのデフォルト値
+const (
+	defaultOutboundQueueSize    = 256
+	defaultOutboundWriteTimeout = 10 * time.Second
+)
+
+type OutboundSetting struct {
+	QueueSize    int
+	WriteTimeout time.Duration
+	FullPolicy   QueuePolicy
+}
+
+func newOutboundSetting(config OutboundConfig) (OutboundSetting, error) {
+	setting := OutboundSetting{
+		QueueSize:    config.QueueSize,
+		WriteTimeout: config.WriteTimeout,
+		FullPolicy:   QP_DROP_OLDEST,
+	}
+	if setting.QueueSize <= 0 {
+		setting.QueueSize = defaultOutboundQueueSize
+	}
+	if setting.WriteTimeout <= 0 {
+		setting.WriteTimeout = defaultOutboundWriteTimeout
+	}
+	if config.FullPolicy != "" {
+		policy, err := QueuePolicyFromString(config.FullPolicy)
+		if err != nil {
+			return OutboundSetting{}, err
+		}
+		setting.FullPolicy = policy
+	}
+	return setting, nil
+}
+
+// OutboundStats は送信キューの統計情報です
+type OutboundStats struct {
+	Depth    int `json:"depth"`
+	MaxDepth int `json:"max_depth"`
+	Sent     int `json:"sent"`
+	Dropped  int `json:"dropped"`
+}
+
+type outboundMessage struct {
+	data []byte
+	done chan error
+}
+
+// OutboundQueue はエージェントへの送信を順番に行うキューです
+// 専用の書き込みgoroutineが書き込み期限付きでキューを送信するため、遅いエージェントが他のエージェントへの送信を妨げません
+type OutboundQueue struct {
+	setting OutboundSetting
+	conn    *websocket.Conn
+	onError func()
+	mu      sync.Mutex
+	space   *sync.Cond
+	items   []outboundMessage
+	stats   OutboundStats
+	failed  error
+	notify  chan struct{}
+	closed  chan struct{}
+	once    sync.Once
+}
+
+func newOutboundQueue(conn *websocket.Conn, setting OutboundSetting, onError func()) *OutboundQueue {
+	q := &OutboundQueue{
+		setting: setting,
+		conn:    conn,
+		onError: onError,
+		notify:  make(chan struct{}, 1),
+		closed:  make(chan struct{}),
+	}
+	q.space = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// Enqueue はパケットをキューに追加し、送信を待たずに戻ります
+// キューが満杯の場合は、設定されたポリシーに従って処理します
+func (q *OutboundQueue) Enqueue(data []byte) error {
+	return q.push(outboundMessage{data: data}, false)
+}
+
+// Send はパケットをキューに追加し、送信が完了するまで待ちます
+// キューが満杯の場合でもポリシーを適用せず、空きができるまで待ちます
+func (q *OutboundQueue) Send(data []byte) error {
+	done := make(chan error, 1)
+	if err := q.push(outboundMessage{data: data, done: done}, true); err != nil {
+		return err
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-q.closed:
+		return errors.New("送信キューが閉じられました")
+	}
+}
+
+func (q *OutboundQueue) push(message outboundMessage, wait bool) error {
+	q.mu.Lock()
+enqueue:
+	for {
+		if err := q.failed; err != nil {
+			q.mu.Unlock()
+			return err
+		}
+		if len(q.items) < q.setting.QueueSize {
+			break enqueue
+		}
+		if wait {
+			select {
+			case <-q.closed:
+				q.mu.Unlock()
+				return errors.New("送信キューが閉じられました")
+			default:
+			}
+			// 書き込みgoroutineがパケットを取り出すか、エラーになるか、閉じられるまで待つ
+			q.space.Wait()
+			continue
+		}
+		switch q.setting.FullPolicy {
+		case QP_DROP_OLDEST:
+			// 送信の完了を待っているパケットは破棄しない
+			idx := slices.IndexFunc(q.items, func(message outboundMessage) bool {
+				return message.done == nil
+			})
+			if idx == -1 {
+				break enqueue
+			}
+			q.items = slices.Delete(q.items, idx, idx+1)
+			q.stats.Dropped++
+			slog.Warn("送信キューが満杯のため、最も古いパケットを破棄しました", "remote_addr", q.conn.RemoteAddr().String())
+			continue
+		case QP_MARK_ERROR:
+			err := errors.New("送信キューが満杯になりました")
+			q.failed = err
+			q.space.Broadcast()
+			q.mu.Unlock()
+			slog.Error("送信キューが満杯のため、エージェントをエラー状態にしました", "remote_addr", q.conn.RemoteAddr().String())
+			q.onError()
+			return err
+		case QP_DISCONNECT:
+			err := errors.New("送信キューが満杯になりました")
+			q.failed = err
+			q.space.Broadcast()
+			q.mu.Unlock()
+			slog.Error("送信キューが満杯のため、エージェントを切断しました", "remote_addr", q.conn.RemoteAddr().String())
+			q.onError()
+			q.conn.Close()
+			return err
+		}
+	}
+	q.items = append(q.items, message)
+	q.stats.Depth = len(q.items)
+	q.stats.MaxDepth = max(q.stats.MaxDepth, q.stats.Depth)
+	q.mu.Unlock()
+
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *OutboundQueue) run() {
+	for {
+		select {
+		case <-q.notify:
+		case <-q.closed:
+			return
+		}
+		for {
+			q.mu.Lock()
+			if q.failed != nil {
+				q.failPending()
+			}
+			if len(q.items) == 0 {
+				q.mu.Unlock()
+				break
+			}
+			message := q.items[0]
+			q.items = q.items[1:]
+			q.stats.Depth = len(q.items)
+			q.space.Broadcast()
+			q.mu.Unlock()
+
+			q.conn.SetWriteDeadline(time.Now().Add(q.setting.WriteTimeout))
+			err := q.conn.WriteMessage(websocket.TextMessage, message.data)
+			if message.done != nil {
+				message.done <- err
+			}
+			q.mu.Lock()
+			if err != nil {
+				q.failed = err
+				q.space.Broadcast()
+			} else {
+				q.stats.Sent++
+			}
+			q.mu.Unlock()
+			if err != nil {
+				slog.Error("パケットの書き込みに失敗しました", "remote_addr", q.conn.RemoteAddr().String(), "error", err)
+				q.onError()
+			}
+		}
+	}
+}
+
+// failPending はエラー後に残っているパケットを破棄し、送信を待っている呼び出し元にエラーを返します
+func (q *OutboundQueue) failPending() {
+	for _, message := range q.items {
+		if message.done != nil {
+			message.done <- q.failed
+		}
+	}
+	q.items = nil
+	q.stats.Depth = 0
+}
+
+// Stats は送信キューの統計情報を返します
+func (q *OutboundQueue) Stats() OutboundStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.stats
+}
+
+// Close は書き込みgoroutineを停止します
+func (q *OutboundQueue) Close() {
+	q.once.Do(func() {
+		close(q.closed)
+		// 空きを待っている送信を終了させる
+		q.mu.Lock()
+		q.space.Broadcast()
+		q.mu.Unlock()
+	})
+}
@@ -1,9 +1,28 @@
 package model
 
+import (
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
 type Packet struct {
-	Request        *Request `json:"request"`
-	Info           *Info    `json:"info,omitempty"`
-	Setting        *Setting `json:"setting,omitempty"`
-	TalkHistory    *[]Talk  `json:"talk_history,omitempty"`
-	WhisperHistory *[]Talk  `json:"whisper_history,omitempty"`
+	RequestID            *string       `json:"request_id,omitempty"`
+	Request              *Request      `json:"request"`
+	Info                 *Info         `json:"info,omitempty"`
+	Setting              *Setting      `json:"setting,omitempty"`
+	TalkHistory          *[]Talk       `json:"talk_history,omitempty"`
+	WhisperHistory       *[]Talk       `json:"whisper_history,omitempty"`
+	MasonTalkHistory     *[]Talk       `json:"mason_talk_history,omitempty"`
+	GraveyardTalkHistory *[]Talk       `json:"graveyard_talk_history,omitempty"`
+	BidRequest           *Request      `json:"bid_request,omitempty"`
+	PromptReason         *PromptReason `json:"prompt_reason,omitempty"`
+	Deadline             *time.Time    `json:"deadline,omitempty"`
+	RemainingTime        *int          `json:"remaining_time,omitempty"`
+	Hello                *Hello        `json:"hello,omitempty"`
+}
+
+// newRequestID はパケットに付けるリクエストIDを生成します
+func newRequestID() string {
+	return ulid.Make().String()
 }
@@ -0,0 +1,12 @@
+package model
+
+type PromptReason string
+
+const (
+	PR_SILENCE PromptReason = "silence"
+	PR_MENTION PromptReason = "mention"
+)
+
+func (p PromptReason) String() string {
+	return string(p)
+}
@@ -1,6 +1,10 @@
 package model
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+)
 
 type Request struct {
 	Type            string
@@ -60,6 +64,36 @@ var (
 	R_WHISPER_END = Request{
 		Type:            "WHISPER_END",
 		RequireResponse: false}
+	R_MASON_TALK = Request{
+		Type:            "MASON_TALK",
+		RequireResponse: true}
+	R_MASON_TALK_START = Request{
+		Type:            "MASON_TALK_START",
+		RequireResponse: false}
+	R_MASON_TALK_BROADCAST = Request{
+		Type:            "MASON_TALK_BROADCAST",
+		RequireResponse: false}
+	R_MASON_TALK_END = Request{
+		Type:            "MASON_TALK_END",
+		RequireResponse: false}
+	R_LAST_WORDS = Request{
+		Type:            "LAST_WORDS",
+		RequireResponse: true}
+	R_GRAVEYARD_TALK = Request{
+		Type:            "GRAVEYARD_TALK",
+		RequireResponse: true}
+	R_BID = Request{
+		Type:            "BID",
+		RequireResponse: true}
+	R_TALK_PROMPT = Request{
+		Type:            "TALK_PROMPT",
+		RequireResponse: true}
+	R_TALK_REMAINING_TIME = Request{
+		Type:            "TALK_REMAINING_TIME",
+		RequireResponse: false}
+	R_HELLO = Request{
+		Type:            "HELLO",
+		RequireResponse: false}
 )
 
 func (r Request) String() string {
@@ -106,6 +140,48 @@ func RequestFromString(s string) Request {
 		return R_WHISPER_BROADCAST
 	case "WHISPER_END":
 		return R_WHISPER_END
+	case "MASON_TALK":
+		return R_MASON_TALK
+	case "MASON_TALK_START":
+		return R_MASON_TALK_START
+	case "MASON_TALK_BROADCAST":
+		return R_MASON_TALK_BROADCAST
+	case "MASON_TALK_END":
+		return R_MASON_TALK_END
+	case "LAST_WORDS":
+		return R_LAST_WORDS
+	case "GRAVEYARD_TALK":
+		return R_GRAVEYARD_TALK
+	case "BID":
+		return R_BID
+	case "TALK_PROMPT":
+		return R_TALK_PROMPT
+	case "TALK_REMAINING_TIME":
+		return R_TALK_REMAINING_TIME
+	case "HELLO":
+		return R_HELLO
+	}
+	if r, ok := customRequests.Load(s); ok {
+		return r.(Request)
 	}
 	return Request{}
 }
+
+var customRequests sync.Map
+
+// RegisterRequest は独自のアクションで使用するリクエストを登録します
+func RegisterRequest(r Request) error {
+	if r.Type == "" {
+		return errors.New("リクエストの種類を指定する必要があります")
+	}
+	if RequestFromString(r.Type) != (Request{}) {
+		return errors.New("リクエストが既に登録されています: " + r.Type)
+	}
+	customRequests.Store(r.Type, r)
+	return nil
+}
+
+func IsCustomRequest(r Request) bool {
+	v, ok := customRequests.Load(r.Type)
+	return ok && v.(Request) == r
+}
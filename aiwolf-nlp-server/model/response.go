@@ -0,0 +1,38 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Response はエージェントがJSON形式で返す構造化されたレスポンスです
+// text または target のどちらかを含む必要があり、それ以外の項目は省略できます
+type Response struct {
+	RequestID *string     `json:"request_id,omitempty"`
+	Text      *string     `json:"text,omitempty"`
+	Target    *string     `json:"target,omitempty"`
+	Rationale *string     `json:"rationale,omitempty"`
+	Usage     *TokenUsage `json:"usage,omitempty"`
+	Model     *string     `json:"model,omitempty"`
+}
+
+// TokenUsage はレスポンスの生成に使用したトークン数です
+type TokenUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// parseReply は受信したフレームからレスポンスとテキストを取り出します
+// 構造化されたレスポンスでない場合は、フレーム全体をテキストとして扱い、レスポンスは nil を返します
+func parseReply(data []byte) (*Response, string) {
+	var response Response
+	if err := json.Unmarshal(data, &response); err == nil {
+		if response.Text != nil {
+			return &response, strings.TrimRight(*response.Text, "\n")
+		}
+		if response.Target != nil {
+			return &response, strings.TrimRight(*response.Target, "\n")
+		}
+	}
+	return nil, strings.TrimRight(string(data), "\n")
+}
@@ -0,0 +1,43 @@
+package model
+
+import "errors"
+
+type ResultReason string
+
+const (
+	RR_ELIMINATION ResultReason = "ELIMINATION"
+	RR_PARITY      ResultReason = "PARITY"
+	RR_MAX_DAY     ResultReason = "MAX_DAY"
+	RR_ERROR_RATIO ResultReason = "ERROR_RATIO"
+)
+
+func (r ResultReason) String() string {
+	return string(r)
+}
+
+type MaxDayOutcome string
+
+const (
+	MO_DRAW      MaxDayOutcome = "draw"
+	MO_WEREWOLF  MaxDayOutcome = "werewolf"
+	MO_VILLAGER  MaxDayOutcome = "villager"
+	MO_SURVIVORS MaxDayOutcome = "survivors"
+)
+
+func MaxDayOutcomeFromString(s string) (MaxDayOutcome, error) {
+	switch s {
+	case "draw":
+		return MO_DRAW, nil
+	case "werewolf":
+		return MO_WEREWOLF, nil
+	case "villager":
+		return MO_VILLAGER, nil
+	case "survivors":
+		return MO_SURVIVORS, nil
+	}
+	return "", errors.New("不明な最大日数到達時の結果です: " + s)
+}
+
+func (m MaxDayOutcome) String() string {
+	return string(m)
+}
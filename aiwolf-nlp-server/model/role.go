@@ -18,6 +18,8 @@ var (
 	R_BODYGUARD = Role{Name: "BODYGUARD", Team: T_VILLAGER, Species: S_HUMAN}
 	R_VILLAGER  = Role{Name: "VILLAGER", Team: T_VILLAGER, Species: S_HUMAN}
 	R_MEDIUM    = Role{Name: "MEDIUM", Team: T_VILLAGER, Species: S_HUMAN}
+	R_FOX       = Role{Name: "FOX", Team: T_FOX, Species: S_HUMAN}
+	R_MASON     = Role{Name: "MASON", Team: T_VILLAGER, Species: S_HUMAN}
 	R_NONE      = Role{Name: "NONE", Team: T_NONE, Species: S_NONE}
 )
 
@@ -26,6 +28,7 @@ type Team string
 const (
 	T_VILLAGER Team = "VILLAGER"
 	T_WEREWOLF Team = "WEREWOLF"
+	T_FOX      Team = "FOX"
 	T_NONE     Team = "NONE"
 )
 
@@ -35,6 +38,8 @@ func TeamFromString(s string) Team {
 		return T_VILLAGER
 	case "WEREWOLF":
 		return T_WEREWOLF
+	case "FOX":
+		return T_FOX
 	}
 	return T_NONE
 }
@@ -79,15 +84,23 @@ func RoleFromString(s string) Role {
 		return R_VILLAGER
 	case "MEDIUM":
 		return R_MEDIUM
+	case "FOX":
+		return R_FOX
+	case "MASON":
+		return R_MASON
 	}
 	return R_NONE
 }
 
 func RolesFromConfig(config Config) (map[Role]int, error) {
+	definitions, err := RoleDefinitionsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
 	roleNumMap := make(map[Role]int)
 	if roles, ok := config.Logic.Roles[config.Game.AgentCount]; ok {
 		for roleName, num := range roles {
-			role := RoleFromString(roleName)
+			role := definitions.Role(roleName)
 			if role == R_NONE {
 				return nil, errors.New("不明な役職名があります")
 			}
@@ -96,5 +109,17 @@ func RolesFromConfig(config Config) (map[Role]int, error) {
 	} else {
 		return nil, errors.New("対応する役職の人数がありません")
 	}
+	foxes, attackers := 0, 0
+	for role, num := range roleNumMap {
+		if role.Team == T_FOX {
+			foxes += num
+		}
+		if definitions.Get(role).HasAbility(A_ATTACK) {
+			attackers += num
+		}
+	}
+	if foxes > 0 && attackers == 0 {
+		return nil, errors.New("妖狐を含む場合は襲撃能力を持つ役職が1人以上必要です")
+	}
 	return roleNumMap, nil
 }
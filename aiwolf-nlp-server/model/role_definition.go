@@ -0,0 +1,164 @@
+package model
+
+import (
+	"errors"
+	"slices"
+)
+
+type Ability string
+
+const (
+	A_DIVINE Ability = "divine"
+	A_GUARD  Ability = "guard"
+	A_ATTACK Ability = "attack"
+	A_MEDIUM Ability = "medium"
+)
+
+func AbilityFromString(s string) (Ability, error) {
+	switch s {
+	case "divine":
+		return A_DIVINE, nil
+	case "guard":
+		return A_GUARD, nil
+	case "attack":
+		return A_ATTACK, nil
+	case "medium":
+		return A_MEDIUM, nil
+	}
+	return "", errors.New("不明な能力があります: " + s)
+}
+
+type Channel string
+
+const (
+	C_WHISPER    Channel = "whisper"
+	C_MASON_TALK Channel = "mason_talk"
+)
+
+func ChannelFromString(s string) (Channel, error) {
+	switch s {
+	case "whisper":
+		return C_WHISPER, nil
+	case "mason_talk":
+		return C_MASON_TALK, nil
+	}
+	return "", errors.New("不明なチャネルがあります: " + s)
+}
+
+type RoleDefinition struct {
+	Role           Role
+	Abilities      []Ability
+	KnownRoles     []string
+	Channels       []Channel
+	ResistAttack   bool
+	CursedByDivine bool
+}
+
+func (d RoleDefinition) HasAbility(ability Ability) bool {
+	return slices.Contains(d.Abilities, ability)
+}
+
+func (d RoleDefinition) CanUse(channel Channel) bool {
+	return slices.Contains(d.Channels, channel)
+}
+
+func (d RoleDefinition) Knows(role Role) bool {
+	return slices.Contains(d.KnownRoles, role.Name)
+}
+
+type RoleDefinitions map[string]RoleDefinition
+
+func (r RoleDefinitions) Get(role Role) RoleDefinition {
+	if definition, ok := r[role.Name]; ok {
+		return definition
+	}
+	return RoleDefinition{Role: role}
+}
+
+func (r RoleDefinitions) Role(name string) Role {
+	if definition, ok := r[name]; ok {
+		return definition.Role
+	}
+	return R_NONE
+}
+
+func DefaultRoleDefinitions() RoleDefinitions {
+	return RoleDefinitions{
+		R_WEREWOLF.Name: {
+			Role:       R_WEREWOLF,
+			Abilities:  []Ability{A_ATTACK},
+			KnownRoles: []string{R_WEREWOLF.Name},
+			Channels:   []Channel{C_WHISPER},
+		},
+		R_POSSESSED.Name: {Role: R_POSSESSED},
+		R_SEER.Name: {
+			Role:      R_SEER,
+			Abilities: []Ability{A_DIVINE},
+		},
+		R_BODYGUARD.Name: {
+			Role:      R_BODYGUARD,
+			Abilities: []Ability{A_GUARD},
+		},
+		R_VILLAGER.Name: {Role: R_VILLAGER},
+		R_MEDIUM.Name: {
+			Role:      R_MEDIUM,
+			Abilities: []Ability{A_MEDIUM},
+		},
+		R_FOX.Name: {
+			Role:           R_FOX,
+			ResistAttack:   true,
+			CursedByDivine: true,
+		},
+		R_MASON.Name: {
+			Role:       R_MASON,
+			KnownRoles: []string{R_MASON.Name},
+			Channels:   []Channel{C_MASON_TALK},
+		},
+	}
+}
+
+func RoleDefinitionsFromConfig(config Config) (RoleDefinitions, error) {
+	definitions := DefaultRoleDefinitions()
+	for _, roleConfig := range config.Logic.RoleDefinitions {
+		if roleConfig.Name == "" || roleConfig.Name == R_NONE.Name {
+			return nil, errors.New("[RoleDefinitions] 役職名が不正です")
+		}
+		team := TeamFromString(roleConfig.Team)
+		if team == T_NONE {
+			return nil, errors.New("[RoleDefinitions] 不明な陣営があります: " + roleConfig.Team)
+		}
+		species := SpeciesFromString(roleConfig.Species)
+		if species == S_NONE {
+			return nil, errors.New("[RoleDefinitions] 不明な種族があります: " + roleConfig.Species)
+		}
+		definition := RoleDefinition{
+			Role:           Role{Name: roleConfig.Name, Team: team, Species: species},
+			KnownRoles:     roleConfig.Knows,
+			ResistAttack:   roleConfig.ResistAttack,
+			CursedByDivine: roleConfig.CursedByDivine,
+		}
+		for _, name := range roleConfig.Abilities {
+			ability, err := AbilityFromString(name)
+			if err != nil {
+				return nil, errors.New("[RoleDefinitions] " + err.Error())
+			}
+			definition.Abilities = append(definition.Abilities, ability)
+		}
+		for _, name := range roleConfig.Channels {
+			channel, err := ChannelFromString(name)
+			if err != nil {
+				return nil, errors.New("[RoleDefinitions] " + err.Error())
+			}
+			definition.Channels = append(definition.Channels, channel)
+		}
+		definitions[roleConfig.Name] = definition
+	}
+	for _, definition := range definitions {
+		for _, name := range definition.KnownRoles {
+			if _, ok := definitions[name]; !ok {
+				return nil, errors.New("[RoleDefinitions] 不明な役職名があります: " + name)
+			}
+		}
+	}
+	return definitions, nil
+}
@@ -3,27 +3,45 @@ package model
 import (
 	"encoding/json"
 	"errors"
+	"slices"
 )
 
 type Setting struct {
-	AgentCount     int          `json:"agent_count"`
-	MaxDay         *int         `json:"max_day,omitempty"`
-	RoleNumMap     map[Role]int `json:"role_num_map"`
-	VoteVisibility bool         `json:"vote_visibility"`
-	Talk           struct {
+	AgentCount      int             `json:"agent_count"`
+	MaxDay          *int            `json:"max_day,omitempty"`
+	MaxDayOutcome   MaxDayOutcome   `json:"max_day_outcome"`
+	RoleNumMap      map[Role]int    `json:"role_num_map"`
+	RoleDefinitions RoleDefinitions `json:"-"`
+	Visibility      Visibility      `json:"-"`
+	Outbound        OutboundSetting `json:"-"`
+	VoteVisibility  bool            `json:"vote_visibility"`
+	Talk            struct {
 		TalkSetting `json:",inline"`
 	} `json:"talk"`
 	Whisper struct {
 		TalkSetting `json:",inline"`
 	} `json:"whisper"`
-	Vote struct {
-		MaxCount      int  `json:"max_count"`
-		AllowSelfVote bool `json:"allow_self_vote"`
+	MasonTalk   *TalkSetting       `json:"mason_talk,omitempty"`
+	LastWords   *LastWordsSetting  `json:"last_words,omitempty"`
+	Guard       GuardSetting       `json:"guard"`
+	NightAction NightActionSetting `json:"night_action"`
+	Spectator   *SpectatorSetting  `json:"spectator,omitempty"`
+	Vote        struct {
+		MaxCount      int            `json:"max_count"`
+		AllowSelfVote bool           `json:"allow_self_vote"`
+		Runoff        bool           `json:"runoff"`
+		TieBreak      TieBreak       `json:"tie_break"`
+		Abstain       AbstainSetting `json:"abstain"`
+		Quorum        float64        `json:"quorum"`
 	} `json:"vote"`
 	AttackVote struct {
-		MaxCount      int  `json:"max_count"`
-		AllowSelfVote bool `json:"allow_self_vote"`
-		AllowNoTarget bool `json:"allow_no_target"`
+		MaxCount      int            `json:"max_count"`
+		AllowSelfVote bool           `json:"allow_self_vote"`
+		AllowNoTarget bool           `json:"allow_no_target"`
+		Runoff        bool           `json:"runoff"`
+		TieBreak      TieBreak       `json:"tie_break"`
+		Abstain       AbstainSetting `json:"abstain"`
+		Quorum        float64        `json:"quorum"`
 	} `json:"attack_vote"`
 	Timeout struct {
 		Action   int `json:"action"`
@@ -44,7 +62,39 @@ type TalkSetting struct {
 		PerAgent      *int  `json:"per_agent,omitempty"`
 		BaseLength    *int  `json:"base_length,omitempty"`
 	} `json:"max_length"`
-	MaxSkip int `json:"max_skip"`
+	MaxSkip int         `json:"max_skip"`
+	Order   TalkOrder   `json:"order"`
+	Bid     *BidSetting `json:"bid,omitempty"`
+}
+
+type BidSetting struct {
+	MaxConsecutive int `json:"max_consecutive"`
+}
+
+type LastWordsSetting struct {
+	MaxLength *int `json:"max_length,omitempty"`
+}
+
+type GuardSetting struct {
+	AllowSelfGuard        bool `json:"allow_self_guard"`
+	BanConsecutiveGuard   bool `json:"ban_consecutive_guard"`
+	AnnounceGuardedAttack bool `json:"announce_guarded_attack"`
+}
+
+type NightActionSetting struct {
+	Simultaneous    bool     `json:"simultaneous"`
+	ResolutionOrder []string `json:"resolution_order"`
+}
+
+type SpectatorSetting struct {
+	RevealRoles   bool         `json:"reveal_roles"`
+	GraveyardTalk *TalkSetting `json:"graveyard_talk,omitempty"`
+}
+
+type AbstainSetting struct {
+	Enable          bool `json:"enable"`
+	CountInQuorum   bool `json:"count_in_quorum"`
+	MajorityCancels bool `json:"majority_cancels"`
 }
 
 func NewSetting(config Config) (*Setting, error) {
@@ -52,6 +102,24 @@ func NewSetting(config Config) (*Setting, error) {
 	if err != nil {
 		return nil, err
 	}
+	roleDefinitions, err := RoleDefinitionsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	visibility, err := VisibilityFromConfig(config, roleDefinitions)
+	if err != nil {
+		return nil, err
+	}
+	for _, phase := range append(slices.Clone(config.Logic.DayPhases), config.Logic.NightPhases...) {
+		if phase.EveryNDays != nil && *phase.EveryNDays <= 0 {
+			return nil, errors.New("[Logic] every_n_daysは1以上である必要があります: " + phase.Name)
+		}
+		if phase.RoleAlive != nil {
+			if _, ok := roleDefinitions[*phase.RoleAlive]; !ok {
+				return nil, errors.New("[Logic] 不明な役職名があります: " + *phase.RoleAlive)
+			}
+		}
+	}
 	if config.CustomProfile.Enable {
 		if config.CustomProfile.DynamicProfile.Enable {
 			if len(config.CustomProfile.DynamicProfile.Avatars) < config.Game.AgentCount {
@@ -63,76 +131,131 @@ func NewSetting(config Config) (*Setting, error) {
 			}
 		}
 	}
-	if config.Game.Talk.MaxLength.CountInWord && config.Game.Talk.MaxLength.CountSpaces{
+	if config.Game.Talk.MaxLength.CountInWord && config.Game.Talk.MaxLength.CountSpaces {
 		return nil, errors.New("[Talk] CountInWordとCountSpacesを両方有効にすることはできません")
 	}
-	if config.Game.Whisper.MaxLength.CountInWord && config.Game.Whisper.MaxLength.CountSpaces{
+	if config.Game.Whisper.MaxLength.CountInWord && config.Game.Whisper.MaxLength.CountSpaces {
 		return nil, errors.New("[Whisper] CountInWordとCountSpacesを両方有効にすることはできません")
 	}
+	if config.Game.MasonTalk != nil && config.Game.MasonTalk.MaxLength.CountInWord && config.Game.MasonTalk.MaxLength.CountSpaces {
+		return nil, errors.New("[MasonTalk] CountInWordとCountSpacesを両方有効にすることはできません")
+	}
+	if config.Game.Spectator.GraveyardTalk != nil {
+		if !config.Game.Spectator.Enable {
+			return nil, errors.New("[Spectator] 墓場チャットを使用するには観戦モードを有効にする必要があります")
+		}
+		if config.Game.Spectator.GraveyardTalk.MaxLength.CountInWord && config.Game.Spectator.GraveyardTalk.MaxLength.CountSpaces {
+			return nil, errors.New("[Spectator] CountInWordとCountSpacesを両方有効にすることはできません")
+		}
+	}
+
+	if config.Game.Vote.Quorum < 0 || config.Game.Vote.Quorum > 1 {
+		return nil, errors.New("[Vote] Quorumは0以上1以下である必要があります")
+	}
+	if config.Game.AttackVote.Quorum < 0 || config.Game.AttackVote.Quorum > 1 {
+		return nil, errors.New("[AttackVote] Quorumは0以上1以下である必要があります")
+	}
+	voteTieBreak := TB_RANDOM
+	if config.Game.Vote.TieBreak != "" {
+		voteTieBreak, err = TieBreakFromString(config.Game.Vote.TieBreak)
+		if err != nil {
+			return nil, errors.New("[Vote] " + err.Error())
+		}
+	}
+	resolutionOrder := []string{"divine", "guard", "attack"}
+	if len(config.Game.NightAction.ResolutionOrder) > 0 {
+		resolutionOrder = config.Game.NightAction.ResolutionOrder
+		for i, action := range resolutionOrder {
+			if slices.Contains(resolutionOrder[:i], action) {
+				return nil, errors.New("[NightAction] 解決順序のアクションが重複しています: " + action)
+			}
+		}
+	}
+	maxDayOutcome := MO_DRAW
+	if config.Game.MaxDayOutcome != "" {
+		maxDayOutcome, err = MaxDayOutcomeFromString(config.Game.MaxDayOutcome)
+		if err != nil {
+			return nil, errors.New("[Game] " + err.Error())
+		}
+	}
+	attackVoteTieBreak := TB_RANDOM
+	if config.Game.AttackVote.AllowNoTarget {
+		attackVoteTieBreak = TB_NONE
+	}
+	if config.Game.AttackVote.TieBreak != "" {
+		attackVoteTieBreak, err = TieBreakFromString(config.Game.AttackVote.TieBreak)
+		if err != nil {
+			return nil, errors.New("[AttackVote] " + err.Error())
+		}
+	}
+
+	talkSetting, err := newTalkSetting(config.Game.Talk)
+	if err != nil {
+		return nil, errors.New("[Talk] " + err.Error())
+	}
+	whisperSetting, err := newTalkSetting(config.Game.Whisper)
+	if err != nil {
+		return nil, errors.New("[Whisper] " + err.Error())
+	}
+	outboundSetting, err := newOutboundSetting(config.Server.Outbound)
+	if err != nil {
+		return nil, errors.New("[Outbound] " + err.Error())
+	}
 
 	setting := Setting{
-		AgentCount:     config.Game.AgentCount,
-		RoleNumMap:     roles,
-		VoteVisibility: config.Game.VoteVisibility,
+		AgentCount:      config.Game.AgentCount,
+		RoleNumMap:      roles,
+		MaxDayOutcome:   maxDayOutcome,
+		RoleDefinitions: roleDefinitions,
+		Visibility:      visibility,
+		Outbound:        outboundSetting,
+		VoteVisibility:  config.Game.VoteVisibility,
 		Talk: struct {
 			TalkSetting `json:",inline"`
 		}{
-			TalkSetting: TalkSetting{
-				MaxCount: struct {
-					PerAgent int `json:"per_agent"`
-					PerDay   int `json:"per_day"`
-				}{
-					PerAgent: config.Game.Talk.MaxCount.PerAgent,
-					PerDay:   config.Game.Talk.MaxCount.PerDay,
-				},
-				MaxLength: struct {
-					CountInWord   *bool `json:"count_in_word,omitempty"`
-					CountSpaces   *bool `json:"count_spaces,omitempty"`
-					PerTalk       *int  `json:"per_talk,omitempty"`
-					MentionLength *int  `json:"mention_length,omitempty"`
-					PerAgent      *int  `json:"per_agent,omitempty"`
-					BaseLength    *int  `json:"base_length,omitempty"`
-				}{},
-				MaxSkip: config.Game.Talk.MaxSkip,
-			},
+			TalkSetting: talkSetting,
 		},
 		Whisper: struct {
 			TalkSetting `json:",inline"`
 		}{
-			TalkSetting: TalkSetting{
-				MaxCount: struct {
-					PerAgent int `json:"per_agent"`
-					PerDay   int `json:"per_day"`
-				}{
-					PerAgent: config.Game.Whisper.MaxCount.PerAgent,
-					PerDay:   config.Game.Whisper.MaxCount.PerDay,
-				},
-				MaxLength: struct {
-					CountInWord   *bool `json:"count_in_word,omitempty"`
-					CountSpaces   *bool `json:"count_spaces,omitempty"`
-					PerTalk       *int  `json:"per_talk,omitempty"`
-					MentionLength *int  `json:"mention_length,omitempty"`
-					PerAgent      *int  `json:"per_agent,omitempty"`
-					BaseLength    *int  `json:"base_length,omitempty"`
-				}{},
-				MaxSkip: config.Game.Whisper.MaxSkip,
-			},
+			TalkSetting: whisperSetting,
+		},
+		Guard: GuardSetting(config.Game.Guard),
+		NightAction: NightActionSetting{
+			Simultaneous:    config.Game.NightAction.Simultaneous,
+			ResolutionOrder: resolutionOrder,
 		},
 		Vote: struct {
-			MaxCount      int  `json:"max_count"`
-			AllowSelfVote bool `json:"allow_self_vote"`
+			MaxCount      int            `json:"max_count"`
+			AllowSelfVote bool           `json:"allow_self_vote"`
+			Runoff        bool           `json:"runoff"`
+			TieBreak      TieBreak       `json:"tie_break"`
+			Abstain       AbstainSetting `json:"abstain"`
+			Quorum        float64        `json:"quorum"`
 		}{
 			MaxCount:      config.Game.Vote.MaxCount,
 			AllowSelfVote: config.Game.Vote.AllowSelfVote,
+			Runoff:        config.Game.Vote.Runoff,
+			TieBreak:      voteTieBreak,
+			Abstain:       AbstainSetting(config.Game.Vote.Abstain),
+			Quorum:        config.Game.Vote.Quorum,
 		},
 		AttackVote: struct {
-			MaxCount      int  `json:"max_count"`
-			AllowSelfVote bool `json:"allow_self_vote"`
-			AllowNoTarget bool `json:"allow_no_target"`
+			MaxCount      int            `json:"max_count"`
+			AllowSelfVote bool           `json:"allow_self_vote"`
+			AllowNoTarget bool           `json:"allow_no_target"`
+			Runoff        bool           `json:"runoff"`
+			TieBreak      TieBreak       `json:"tie_break"`
+			Abstain       AbstainSetting `json:"abstain"`
+			Quorum        float64        `json:"quorum"`
 		}{
 			MaxCount:      config.Game.AttackVote.MaxCount,
 			AllowSelfVote: config.Game.AttackVote.AllowSelfVote,
 			AllowNoTarget: config.Game.AttackVote.AllowNoTarget,
+			Runoff:        config.Game.AttackVote.Runoff,
+			TieBreak:      attackVoteTieBreak,
+			Abstain:       AbstainSetting(config.Game.AttackVote.Abstain),
+			Quorum:        config.Game.AttackVote.Quorum,
 		},
 		Timeout: struct {
 			Action   int `json:"action"`
@@ -145,43 +268,77 @@ func NewSetting(config Config) (*Setting, error) {
 	if config.Game.MaxDay != -1 {
 		setting.MaxDay = &config.Game.MaxDay
 	}
-	if config.Game.Talk.MaxLength.PerTalk != -1 {
-		setting.Talk.MaxLength.CountInWord = &config.Game.Talk.MaxLength.CountInWord
-		setting.Talk.MaxLength.CountSpaces = &config.Game.Talk.MaxLength.CountSpaces
-		setting.Talk.MaxLength.PerTalk = &config.Game.Talk.MaxLength.PerTalk
-	}
-	if config.Game.Talk.MaxLength.PerAgent != -1 {
-		setting.Talk.MaxLength.CountInWord = &config.Game.Talk.MaxLength.CountInWord
-		setting.Talk.MaxLength.CountSpaces = &config.Game.Talk.MaxLength.CountSpaces
-		setting.Talk.MaxLength.PerAgent = &config.Game.Talk.MaxLength.PerAgent
-		setting.Talk.MaxLength.MentionLength = &config.Game.Talk.MaxLength.MentionLength
-	}
-	if config.Game.Talk.MaxLength.BaseLength != -1 {
-		setting.Talk.MaxLength.CountInWord = &config.Game.Talk.MaxLength.CountInWord
-		setting.Talk.MaxLength.CountSpaces = &config.Game.Talk.MaxLength.CountSpaces
-		setting.Talk.MaxLength.BaseLength = &config.Game.Talk.MaxLength.BaseLength
-		setting.Talk.MaxLength.MentionLength = &config.Game.Talk.MaxLength.MentionLength
-	}
-	if config.Game.Whisper.MaxLength.PerTalk != -1 {
-		setting.Whisper.MaxLength.CountInWord = &config.Game.Whisper.MaxLength.CountInWord
-		setting.Whisper.MaxLength.CountSpaces = &config.Game.Whisper.MaxLength.CountSpaces
-		setting.Whisper.MaxLength.PerTalk = &config.Game.Whisper.MaxLength.PerTalk
-	}
-	if config.Game.Whisper.MaxLength.PerAgent != -1 {
-		setting.Whisper.MaxLength.CountInWord = &config.Game.Whisper.MaxLength.CountInWord
-		setting.Whisper.MaxLength.CountSpaces = &config.Game.Whisper.MaxLength.CountSpaces
-		setting.Whisper.MaxLength.PerAgent = &config.Game.Whisper.MaxLength.PerAgent
-		setting.Whisper.MaxLength.MentionLength = &config.Game.Whisper.MaxLength.MentionLength
-	}
-	if config.Game.Whisper.MaxLength.BaseLength != -1 {
-		setting.Whisper.MaxLength.CountInWord = &config.Game.Whisper.MaxLength.CountInWord
-		setting.Whisper.MaxLength.CountSpaces = &config.Game.Whisper.MaxLength.CountSpaces
-		setting.Whisper.MaxLength.BaseLength = &config.Game.Whisper.MaxLength.BaseLength
-		setting.Whisper.MaxLength.MentionLength = &config.Game.Whisper.MaxLength.MentionLength
+	if config.Game.MasonTalk != nil {
+		masonTalk, err := newTalkSetting(*config.Game.MasonTalk)
+		if err != nil {
+			return nil, errors.New("[MasonTalk] " + err.Error())
+		}
+		setting.MasonTalk = &masonTalk
+	}
+	if config.Game.Spectator.Enable {
+		setting.Spectator = &SpectatorSetting{
+			RevealRoles: config.Game.Spectator.RevealRoles,
+		}
+		if config.Game.Spectator.GraveyardTalk != nil {
+			graveyardTalk, err := newTalkSetting(*config.Game.Spectator.GraveyardTalk)
+			if err != nil {
+				return nil, errors.New("[Spectator] " + err.Error())
+			}
+			setting.Spectator.GraveyardTalk = &graveyardTalk
+		}
+	}
+	if config.Game.LastWords.Enable {
+		setting.LastWords = &LastWordsSetting{}
+		// 省略した場合は 0 となるため、-1 と同様に制限無しとして扱う
+		if config.Game.LastWords.MaxLength > 0 {
+			setting.LastWords.MaxLength = &config.Game.LastWords.MaxLength
+		}
 	}
 	return &setting, nil
 }
 
+func newTalkSetting(config TalkConfig) (TalkSetting, error) {
+	setting := TalkSetting{
+		MaxSkip: config.MaxSkip,
+		Order:   TO_SHUFFLE,
+	}
+	if config.Order != "" {
+		order, err := TalkOrderFromString(config.Order)
+		if err != nil {
+			return TalkSetting{}, err
+		}
+		setting.Order = order
+	}
+	if setting.Order == TO_BID {
+		if config.Bid.MaxConsecutive < 0 {
+			return TalkSetting{}, errors.New("連続して発言権を得られる回数は0以上である必要があります")
+		}
+		setting.Bid = &BidSetting{
+			MaxConsecutive: config.Bid.MaxConsecutive,
+		}
+	}
+	setting.MaxCount.PerAgent = config.MaxCount.PerAgent
+	setting.MaxCount.PerDay = config.MaxCount.PerDay
+	if config.MaxLength.PerTalk != -1 {
+		setting.MaxLength.CountInWord = &config.MaxLength.CountInWord
+		setting.MaxLength.CountSpaces = &config.MaxLength.CountSpaces
+		setting.MaxLength.PerTalk = &config.MaxLength.PerTalk
+	}
+	if config.MaxLength.PerAgent != -1 {
+		setting.MaxLength.CountInWord = &config.MaxLength.CountInWord
+		setting.MaxLength.CountSpaces = &config.MaxLength.CountSpaces
+		setting.MaxLength.PerAgent = &config.MaxLength.PerAgent
+		setting.MaxLength.MentionLength = &config.MaxLength.MentionLength
+	}
+	if config.MaxLength.BaseLength != -1 {
+		setting.MaxLength.CountInWord = &config.MaxLength.CountInWord
+		setting.MaxLength.CountSpaces = &config.MaxLength.CountSpaces
+		setting.MaxLength.BaseLength = &config.MaxLength.BaseLength
+		setting.MaxLength.MentionLength = &config.MaxLength.MentionLength
+	}
+	return setting, nil
+}
+
 func (s Setting) MarshalJSON() ([]byte, error) {
 	roleNumMap := make(map[string]int)
 	for k, v := range s.RoleNumMap {
@@ -3,11 +3,13 @@ package model
 import "encoding/json"
 
 type Talk struct {
-	Idx   int    `json:"idx"`
-	Day   int    `json:"day"`
-	Turn  int    `json:"turn"`
-	Agent Agent  `json:"agent"`
-	Text  string `json:"text"`
+	Idx       int    `json:"idx"`
+	Day       int    `json:"day"`
+	Turn      int    `json:"turn"`
+	Position  int    `json:"position"`
+	Agent     Agent  `json:"agent"`
+	Text      string `json:"text"`
+	LastWords bool   `json:"last_words,omitempty"`
 }
 
 func (t Talk) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,36 @@
+package model
+
+import "errors"
+
+type TalkOrder string
+
+const (
+	TO_SHUFFLE   TalkOrder = "shuffle"
+	TO_SEAT      TalkOrder = "seat"
+	TO_ROTATE    TalkOrder = "rotate"
+	TO_RESHUFFLE TalkOrder = "reshuffle"
+	TO_MENTION   TalkOrder = "mention"
+	TO_BID       TalkOrder = "bid"
+)
+
+func TalkOrderFromString(s string) (TalkOrder, error) {
+	switch s {
+	case "shuffle":
+		return TO_SHUFFLE, nil
+	case "seat":
+		return TO_SEAT, nil
+	case "rotate":
+		return TO_ROTATE, nil
+	case "reshuffle":
+		return TO_RESHUFFLE, nil
+	case "mention":
+		return TO_MENTION, nil
+	case "bid":
+		return TO_BID, nil
+	}
+	return "", errors.New("不明な発言順です: " + s)
+}
+
+func (t TalkOrder) String() string {
+	return string(t)
+}
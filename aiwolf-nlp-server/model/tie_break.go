@@ -0,0 +1,27 @@
+package model
+
+import "errors"
+
+type TieBreak string
+
+const (
+	TB_RANDOM TieBreak = "random"
+	TB_NONE   TieBreak = "none"
+	TB_ALL    TieBreak = "all"
+)
+
+func TieBreakFromString(s string) (TieBreak, error) {
+	switch s {
+	case "random":
+		return TB_RANDOM, nil
+	case "none":
+		return TB_NONE, nil
+	case "all":
+		return TB_ALL, nil
+	}
+	return "", errors.New("不明な同票時の処理です: " + s)
+}
+
+func (t TieBreak) String() string {
+	return string(t)
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"errors"
+	"slices"
+)
+
+type VisibilityField string
+
+const (
+	VF_VOTE_LIST        VisibilityField = "vote_list"
+	VF_ATTACK_VOTE_LIST VisibilityField = "attack_vote_list"
+	VF_MEDIUM_RESULT    VisibilityField = "medium_result"
+	VF_DIVINE_RESULT    VisibilityField = "divine_result"
+	VF_WHISPER          VisibilityField = "whisper"
+	VF_MASON_TALK       VisibilityField = "mason_talk"
+	VF_GUARDED_AGENT    VisibilityField = "guarded_agent"
+	VF_EXECUTED_ROLE    VisibilityField = "executed_role"
+)
+
+// allRolesName は全役職を表す役職名です
+const allRolesName = "ALL"
+
+// Visibility は役職ごとに参照できる情報を表します
+type Visibility struct {
+	fields  map[VisibilityField][]string
+	roleMap map[string][]string
+}
+
+// CanSee は役職が情報を参照できるかどうかを返します
+func (v Visibility) CanSee(field VisibilityField, role Role) bool {
+	return slices.Contains(v.fields[field], role.Name)
+}
+
+// Knows は役職が対象の役職を役職一覧で知ることができるかどうかを返します
+// 役職の定義の knows に加えて判定します
+func (v Visibility) Knows(role Role, target Role) bool {
+	return slices.Contains(v.roleMap[target.Name], role.Name)
+}
+
+// VisibilityFromConfig は設定ファイルの可視性の設定を読み込みます
+// 設定されていない情報は、投票結果の公開などの既存の設定と役職の定義から決定します
+func VisibilityFromConfig(config Config, definitions RoleDefinitions) (Visibility, error) {
+	allRoles := make([]string, 0, len(definitions))
+	for name := range definitions {
+		allRoles = append(allRoles, name)
+	}
+	slices.Sort(allRoles)
+	rolesWith := func(match func(definition RoleDefinition) bool) []string {
+		return slices.DeleteFunc(slices.Clone(allRoles), func(name string) bool {
+			return !match(definitions[name])
+		})
+	}
+	resolve := func(names []string) ([]string, error) {
+		roles := make([]string, 0)
+		for _, name := range names {
+			if name == allRolesName {
+				return slices.Clone(allRoles), nil
+			}
+			if _, ok := definitions[name]; !ok {
+				return nil, errors.New("[Visibility] 不明な役職名があります: " + name)
+			}
+			roles = append(roles, name)
+		}
+		return roles, nil
+	}
+
+	defaults := map[VisibilityField][]string{
+		VF_MEDIUM_RESULT: rolesWith(func(d RoleDefinition) bool { return d.HasAbility(A_MEDIUM) }),
+		VF_DIVINE_RESULT: rolesWith(func(d RoleDefinition) bool { return d.HasAbility(A_DIVINE) }),
+		VF_WHISPER:       rolesWith(func(d RoleDefinition) bool { return d.CanUse(C_WHISPER) }),
+		VF_MASON_TALK:    rolesWith(func(d RoleDefinition) bool { return d.CanUse(C_MASON_TALK) }),
+		VF_EXECUTED_ROLE: {},
+	}
+	if config.Game.VoteVisibility {
+		defaults[VF_VOTE_LIST] = slices.Clone(allRoles)
+		defaults[VF_ATTACK_VOTE_LIST] = rolesWith(func(d RoleDefinition) bool { return d.HasAbility(A_ATTACK) })
+	}
+	if config.Game.Guard.AnnounceGuardedAttack {
+		defaults[VF_GUARDED_AGENT] = slices.Clone(allRoles)
+	}
+
+	visibilityConfig := config.Logic.Visibility
+	configured := map[VisibilityField]*[]string{
+		VF_VOTE_LIST:        visibilityConfig.VoteList,
+		VF_ATTACK_VOTE_LIST: visibilityConfig.AttackVoteList,
+		VF_MEDIUM_RESULT:    visibilityConfig.MediumResult,
+		VF_DIVINE_RESULT:    visibilityConfig.DivineResult,
+		VF_WHISPER:          visibilityConfig.Whisper,
+		VF_MASON_TALK:       visibilityConfig.MasonTalk,
+		VF_GUARDED_AGENT:    visibilityConfig.GuardedAgent,
+		VF_EXECUTED_ROLE:    visibilityConfig.ExecutedRole,
+	}
+	visibility := Visibility{
+		fields:  make(map[VisibilityField][]string),
+		roleMap: make(map[string][]string),
+	}
+	for field, names := range configured {
+		if names == nil {
+			visibility.fields[field] = defaults[field]
+			continue
+		}
+		roles, err := resolve(*names)
+		if err != nil {
+			return Visibility{}, err
+		}
+		visibility.fields[field] = roles
+	}
+	for target, names := range visibilityConfig.RoleMap {
+		if _, ok := definitions[target]; !ok {
+			return Visibility{}, errors.New("[Visibility] 不明な役職名があります: " + target)
+		}
+		roles, err := resolve(names)
+		if err != nil {
+			return Visibility{}, err
+		}
+		visibility.roleMap[target] = roles
+	}
+	return visibility, nil
+}
@@ -1,7 +1,30 @@
 package model
 
+import "encoding/json"
+
 type Vote struct {
-	Day    int   `json:"day"`
-	Agent  Agent `json:"agent"`
-	Target Agent `json:"target"`
+	Day     int   `json:"day"`
+	Agent   Agent `json:"agent"`
+	Target  Agent `json:"target"`
+	Round   int   `json:"round"`
+	Abstain bool  `json:"abstain"`
+}
+
+func (v Vote) MarshalJSON() ([]byte, error) {
+	type Alias Vote
+	var target *Agent
+	if !v.Abstain {
+		target = &v.Target
+	}
+	return json.Marshal(&struct {
+		*Alias
+		Target *Agent `json:"target"`
+	}{
+		Alias:  (*Alias)(&v),
+		Target: target,
+	})
 }
+
+const (
+	V_ABSTAIN = "Abstain"
+)
@@ -22,9 +22,11 @@ type JSONLogger struct {
 
 type JSONLog struct {
 	id           string
+	seed         int64
 	filename     string
 	agents       []any
 	winSide      model.Team
+	resultReason model.ResultReason
 	entries      []any
 	timestampMap sync.Map
 	requestMap   sync.Map
@@ -37,9 +39,10 @@ func NewJSONLogger(config model.Config) *JSONLogger {
 	}
 }
 
-func (j *JSONLogger) TrackStartGame(id string, agents []*model.Agent) {
+func (j *JSONLogger) TrackStartGame(id string, seed int64, agents []*model.Agent) {
 	data := &JSONLog{
 		id:      id,
+		seed:    seed,
 		agents:  make([]any, 0),
 		entries: make([]any, 0),
 		winSide: model.T_NONE,
@@ -71,10 +74,11 @@ func (j *JSONLogger) TrackStartGame(id string, agents []*model.Agent) {
 	j.data.Store(id, data)
 }
 
-func (j *JSONLogger) TrackEndGame(id string, winSide model.Team) {
+func (j *JSONLogger) TrackEndGame(id string, winSide model.Team, resultReason model.ResultReason) {
 	if dataInterface, exists := j.data.Load(id); exists {
 		data := dataInterface.(*JSONLog)
 		data.winSide = winSide
+		data.resultReason = resultReason
 		j.saveGameData(id)
 		j.data.Delete(id)
 	}
@@ -83,12 +87,12 @@ func (j *JSONLogger) TrackEndGame(id string, winSide model.Team) {
 func (j *JSONLogger) TrackStartRequest(id string, agent model.Agent, packet model.Packet) {
 	if dataInterface, exists := j.data.Load(id); exists {
 		data := dataInterface.(*JSONLog)
-		data.timestampMap.Store(agent.OriginalName, time.Now().UnixNano())
-		data.requestMap.Store(agent.OriginalName, packet)
+		data.timestampMap.Store(agent.String(), time.Now().UnixNano())
+		data.requestMap.Store(agent.String(), packet)
 	}
 }
 
-func (j *JSONLogger) TrackEndRequest(id string, agent model.Agent, response string, err error) {
+func (j *JSONLogger) TrackEndRequest(id string, agent model.Agent, response string, structured *model.Response, err error) {
 	if dataInterface, exists := j.data.Load(id); exists {
 		data := dataInterface.(*JSONLog)
 		timestamp := time.Now().UnixNano()
@@ -98,11 +102,11 @@ func (j *JSONLogger) TrackEndRequest(id string, agent model.Agent, response stri
 			"response_timestamp": timestamp / 1e6,
 		}
 
-		if requestTimestampInterface, exists := data.timestampMap.LoadAndDelete(agent.OriginalName); exists {
+		if requestTimestampInterface, exists := data.timestampMap.LoadAndDelete(agent.String()); exists {
 			entry["request_timestamp"] = requestTimestampInterface.(int64) / 1e6
 		}
 
-		if requestInterface, exists := data.requestMap.LoadAndDelete(agent.OriginalName); exists {
+		if requestInterface, exists := data.requestMap.LoadAndDelete(agent.String()); exists {
 			if jsonData, marshalErr := json.Marshal(requestInterface); marshalErr == nil {
 				entry["request"] = string(jsonData)
 			}
@@ -112,6 +116,19 @@ func (j *JSONLogger) TrackEndRequest(id string, agent model.Agent, response stri
 			entry["response"] = response
 		}
 
+		// 理由は他のエージェントには共有せず、ログにのみ記録する
+		if structured != nil {
+			if structured.Rationale != nil {
+				entry["rationale"] = *structured.Rationale
+			}
+			if structured.Usage != nil {
+				entry["usage"] = structured.Usage
+			}
+			if structured.Model != nil {
+				entry["model"] = *structured.Model
+			}
+		}
+
 		if err != nil {
 			entry["error"] = err.Error()
 		}
@@ -124,18 +141,42 @@ func (j *JSONLogger) TrackEndRequest(id string, agent model.Agent, response stri
 	}
 }
 
+func (j *JSONLogger) TrackError(id string, agent model.Agent, err error) {
+	if dataInterface, exists := j.data.Load(id); exists {
+		data := dataInterface.(*JSONLog)
+
+		entry := map[string]any{
+			"agent":           agent.String(),
+			"error_timestamp": time.Now().UnixNano() / 1e6,
+			"error":           err.Error(),
+		}
+		if _, ok := err.(json.Marshaler); ok {
+			entry["detail"] = err
+		}
+
+		data.mu.Lock()
+		data.entries = append(data.entries, entry)
+		data.mu.Unlock()
+
+		j.saveGameData(id)
+	}
+}
+
 func (j *JSONLogger) saveGameData(id string) {
 	if dataInterface, exists := j.data.Load(id); exists {
 		data := dataInterface.(*JSONLog)
 
+		// 並列に保存された場合に、古い内容で上書きされないようにする
 		data.mu.Lock()
+		defer data.mu.Unlock()
 		game := map[string]any{
-			"game_id":  id,
-			"win_side": data.winSide,
-			"agents":   data.agents,
-			"entries":  slices.Clone(data.entries),
+			"game_id":       id,
+			"seed":          data.seed,
+			"win_side":      data.winSide,
+			"result_reason": data.resultReason,
+			"agents":        data.agents,
+			"entries":       slices.Clone(data.entries),
 		}
-		data.mu.Unlock()
 
 		jsonData, err := json.Marshal(game)
 		if err != nil {
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/logic"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var R_POISON = model.Request{
+	Type:            "POISON",
+	RequireResponse: true,
+}
+
+func init() {
+	err := logic.RegisterAction("poison", logic.TargetAction{
+		Request: R_POISON,
+		Actors: func(g *logic.Game) []*model.Agent {
+			actors := make([]*model.Agent, 0)
+			for _, agent := range g.GetAliveAgents() {
+				if agent.Role == model.R_SEER {
+					actors = append(actors, agent)
+				}
+			}
+			return actors
+		},
+		Validate: func(g *logic.Game, agent *model.Agent, target *model.Agent) error {
+			if agent == target {
+				return errors.New("自分自身は対象にできません")
+			}
+			return nil
+		},
+		Apply: func(g *logic.Game, agent *model.Agent, target *model.Agent) {
+			g.GetCurrentGameStatus().StatusMap[*target] = model.S_DEAD
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestAction1(t *testing.T) {
+	t.Log("アクション: 登録した独自のアクションをフェーズで実行できる")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Logic.NightPhases = []model.Phase{
+		{Name: "poison", Actions: []string{"poison"}},
+	}
+
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_DEAD,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeAction(t, "VILLAGER-A", expectStatuses, config)
+}
+
+func TestAction2(t *testing.T) {
+	t.Log("アクション: 独自のアクションの対象の検証に失敗した場合、状態は変更されない")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Logic.NightPhases = []model.Phase{
+		{Name: "poison", Actions: []string{"poison"}},
+	}
+
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeAction(t, "SEER", expectStatuses, config)
+}
+
+func TestAction3(t *testing.T) {
+	t.Log("アクション: 未登録のアクションを含む設定は検証でエラーになる")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	assert.NoError(t, logic.ValidateConfig(*config))
+
+	config.Logic.NightPhases = []model.Phase{
+		{Name: "poison", Actions: []string{"poison", "revive"}},
+	}
+	assert.Error(t, logic.ValidateConfig(*config))
+}
+
+func TestAction4(t *testing.T) {
+	t.Log("アクション: 同じ名前のアクションは登録できない")
+	assert.Error(t, logic.RegisterAction("execution", logic.ActionFunc(func(g *logic.Game) {})))
+}
+
+func executeAction(t *testing.T, target string, expectStatuses []map[string]model.Status, config *model.Config) {
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		R_POISON: func(tc TestClient) (string, error) {
+			assert.Equal(t, "SEER", tc.originalName)
+			// 自分自身が対象の場合は、他のクライアントの登録を待たずに自身の情報から決める
+			if target == tc.originalName {
+				return tc.gameName, nil
+			}
+			return names.get(target), nil
+		},
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			return tc.validateStatusPattern(expectStatuses, names.snapshot())
+		},
+	}
+	executeGame(t, players, config, handlers)
+}
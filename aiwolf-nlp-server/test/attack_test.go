@@ -1,7 +1,6 @@
 package test
 
 import (
-	"sync"
 	"testing"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
@@ -119,26 +118,22 @@ func TestAttackPhase5(t *testing.T) {
 }
 
 func executeAttackPhase(t *testing.T, targetMap map[string]string, expectStatuses []map[string]model.Status, config *model.Config) {
-	nameMap := make(map[string]string)
-	var mu sync.Mutex
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
 
 	handlers := map[model.Request]func(tc TestClient) (string, error){
 		model.R_INITIALIZE: func(tc TestClient) (string, error) {
-			mu.Lock()
-			nameMap[tc.originalName] = tc.gameName
-			mu.Unlock()
+			names.register(tc)
 			return "", nil
 		},
 		model.R_ATTACK: func(tc TestClient) (string, error) {
-			mu.Lock()
-			target := nameMap[targetMap[tc.originalName]]
-			mu.Unlock()
+			target := names.get(targetMap[tc.originalName])
 			tc.t.Logf("襲撃投票: %s -> %s", tc.gameName, target)
 			return target, nil
 		},
 		model.R_FINISH: func(tc TestClient) (string, error) {
-			return tc.validateStatusPattern(expectStatuses, nameMap)
+			return tc.validateStatusPattern(expectStatuses, names.snapshot())
 		},
 	}
-	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+	executeGame(t, players, config, handlers)
 }
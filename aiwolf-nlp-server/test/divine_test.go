@@ -3,6 +3,7 @@ package test
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
 	"github.com/stretchr/testify/assert"
@@ -55,10 +56,16 @@ func executeDivinePhase(t *testing.T, targetRole model.Role, expectSpecies model
 			return "", nil
 		},
 		model.R_DIVINE: func(tc TestClient) (string, error) {
-			mu.Lock()
-			defer mu.Unlock()
-			if gameNames, exists := roleMapping[targetRole]; exists {
-				return gameNames[0], nil
+			// 占い対象のINITIALIZEが処理される前に占いのリクエストを受信する場合があるため、登録されるまで待つ
+			deadline := time.Now().Add(nameRegistryTimeout)
+			for time.Now().Before(deadline) {
+				mu.Lock()
+				gameNames, exists := roleMapping[targetRole]
+				mu.Unlock()
+				if exists {
+					return gameNames[0], nil
+				}
+				time.Sleep(10 * time.Millisecond)
 			}
 			tc.t.Errorf("占い対象が見つかりません: %s", targetRole)
 			return "", nil
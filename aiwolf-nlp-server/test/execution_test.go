@@ -1,10 +1,13 @@
 package test
 
 import (
+	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestExecutionPhase1(t *testing.T) {
@@ -152,27 +155,557 @@ func TestExecutionPhase5(t *testing.T) {
 	executeExecutionPhase(t, targetMap, expectStatuses, config)
 }
 
-func executeExecutionPhase(t *testing.T, targetMap map[string]string, expectStatuses []map[string]model.Status, config *model.Config) {
-	nameMap := make(map[string]string)
+func TestExecutionPhase6(t *testing.T) {
+	t.Log("追放フェーズ: 決選投票が有効な場合、同票の候補者以外への投票は無視される")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Vote.MaxCount = 2
+	config.Game.Vote.Runoff = true
+
+	targetMap := map[string]string{
+		"WEREWOLF":   "VILLAGER-B",
+		"POSSESSED":  "WEREWOLF",
+		"SEER":       "WEREWOLF",
+		"VILLAGER-A": "POSSESSED",
+		"VILLAGER-B": "POSSESSED",
+	}
+	runoffTargetMap := map[string]string{
+		"WEREWOLF":   "VILLAGER-B",
+		"POSSESSED":  "VILLAGER-B",
+		"SEER":       "WEREWOLF",
+		"VILLAGER-A": "VILLAGER-B",
+		"VILLAGER-B": "SEER",
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_DEAD,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeRunoffPhase(t, targetMap, runoffTargetMap, []string{"WEREWOLF", "POSSESSED"}, expectStatuses, config)
+}
+
+func TestExecutionPhase7(t *testing.T) {
+	t.Log("追放フェーズ: 同票時の処理がnoneの場合、誰も追放されない")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Vote.TieBreak = "none"
+
+	targetMap := map[string]string{
+		"WEREWOLF":   "VILLAGER-B",
+		"POSSESSED":  "WEREWOLF",
+		"SEER":       "WEREWOLF",
+		"VILLAGER-A": "POSSESSED",
+		"VILLAGER-B": "POSSESSED",
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeExecutionPhase(t, targetMap, expectStatuses, config)
+}
+
+func TestExecutionPhase8(t *testing.T) {
+	t.Log("追放フェーズ: 同票時の処理がallの場合、同票の候補者全員が追放される")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Vote.TieBreak = "all"
+
+	targetMap := map[string]string{
+		"WEREWOLF":   "VILLAGER-B",
+		"POSSESSED":  "WEREWOLF",
+		"SEER":       "WEREWOLF",
+		"VILLAGER-A": "POSSESSED",
+		"VILLAGER-B": "POSSESSED",
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_DEAD,
+			"POSSESSED":  model.S_DEAD,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeExecutionPhase(t, targetMap, expectStatuses, config)
+}
+
+func TestExecutionPhase9(t *testing.T) {
+	t.Log("追放フェーズ: 再投票を行った場合、すべての回の投票結果が回数とともに記録される")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Vote.MaxCount = 2
+	config.Game.VoteVisibility = true
+
+	targetMap := map[string]string{
+		"WEREWOLF":   "VILLAGER-B",
+		"POSSESSED":  "WEREWOLF",
+		"SEER":       "WEREWOLF",
+		"VILLAGER-A": "POSSESSED",
+		"VILLAGER-B": "POSSESSED",
+	}
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			return names.get(targetMap[tc.originalName]), nil
+		},
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			voteList, exists := tc.info["vote_list"].([]any)
+			if !exists {
+				return "", errors.New("vote_listが見つかりません")
+			}
+			rounds := make(map[int]int)
+			for _, vote := range voteList {
+				rounds[int(vote.(map[string]any)["round"].(float64))]++
+			}
+			assert.Equal(t, map[int]int{0: 5, 1: 5}, rounds)
+			return "", nil
+		},
+	}
+	executeGame(t, players, config, handlers)
+}
+
+func TestExecutionPhase10(t *testing.T) {
+	t.Log("追放フェーズ: 棄権が有効な場合、棄権以外の投票で追放が決まる")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Vote.Abstain.Enable = true
+
+	targetMap := map[string]string{
+		"WEREWOLF":   model.V_ABSTAIN,
+		"POSSESSED":  model.V_ABSTAIN,
+		"SEER":       "WEREWOLF",
+		"VILLAGER-A": "WEREWOLF",
+		"VILLAGER-B": "POSSESSED",
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_DEAD,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeExecutionPhase(t, targetMap, expectStatuses, config)
+}
+
+func TestExecutionPhase11(t *testing.T) {
+	t.Log("追放フェーズ: 棄権が過半数で取り消しになる場合、誰も追放されない")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Vote.Abstain.Enable = true
+	config.Game.Vote.Abstain.MajorityCancels = true
+
+	targetMap := map[string]string{
+		"WEREWOLF":   model.V_ABSTAIN,
+		"POSSESSED":  model.V_ABSTAIN,
+		"SEER":       model.V_ABSTAIN,
+		"VILLAGER-A": "WEREWOLF",
+		"VILLAGER-B": "WEREWOLF",
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeExecutionPhase(t, targetMap, expectStatuses, config)
+}
+
+func TestExecutionPhase12(t *testing.T) {
+	t.Log("追放フェーズ: 棄権を定足数に含めない場合、定足数に達しなければ誰も追放されない")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Vote.Abstain.Enable = true
+	config.Game.Vote.Quorum = 0.5
+
+	targetMap := map[string]string{
+		"WEREWOLF":   model.V_ABSTAIN,
+		"POSSESSED":  model.V_ABSTAIN,
+		"SEER":       model.V_ABSTAIN,
+		"VILLAGER-A": "WEREWOLF",
+		"VILLAGER-B": "WEREWOLF",
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeExecutionPhase(t, targetMap, expectStatuses, config)
+}
+
+func TestExecutionPhase13(t *testing.T) {
+	t.Log("追放フェーズ: 棄権を定足数に含める場合、定足数に達すれば追放される")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Vote.Abstain.Enable = true
+	config.Game.Vote.Abstain.CountInQuorum = true
+	config.Game.Vote.Quorum = 0.5
+
+	targetMap := map[string]string{
+		"WEREWOLF":   model.V_ABSTAIN,
+		"POSSESSED":  model.V_ABSTAIN,
+		"SEER":       model.V_ABSTAIN,
+		"VILLAGER-A": "WEREWOLF",
+		"VILLAGER-B": "WEREWOLF",
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_DEAD,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeExecutionPhase(t, targetMap, expectStatuses, config)
+}
+
+func TestExecutionPhase14(t *testing.T) {
+	t.Log("追放フェーズ: 追放されたプレイヤーの遺言が翌日のトーク履歴に追加される")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.MaxDay = 1
+	config.Game.LastWords.Enable = true
+	config.Game.LastWords.MaxLength = 5
+	config.Logic.DayPhases = []model.Phase{
+		{Name: "talk", Actions: []string{"talk"}},
+	}
+
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+	var mu sync.Mutex
+	lastWordsCount := 0
+	checkedCount := 0
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			return names.get("VILLAGER-B"), nil
+		},
+		model.R_LAST_WORDS: func(tc TestClient) (string, error) {
+			assert.Equal(t, "VILLAGER-B", tc.originalName)
+			mu.Lock()
+			lastWordsCount++
+			mu.Unlock()
+			return "あとは任せました", nil
+		},
+		model.R_TALK: func(tc TestClient) (string, error) {
+			if int(tc.info["day"].(float64)) != 1 {
+				return model.T_OVER, nil
+			}
+			lastWords := make([]map[string]any, 0)
+			for _, talk := range tc.talkHistory {
+				if isLastWords, _ := talk.(map[string]any)["last_words"].(bool); isLastWords {
+					lastWords = append(lastWords, talk.(map[string]any))
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if assert.Equal(t, 1, len(lastWords)) {
+				assert.Equal(t, names.get("VILLAGER-B"), lastWords[0]["agent"])
+				assert.Equal(t, "あとは任せ", lastWords[0]["text"])
+				assert.Equal(t, float64(1), lastWords[0]["day"])
+			}
+			checkedCount++
+			return model.T_OVER, nil
+		},
+	}
+	executeGame(t, players, config, handlers)
+
+	assert.Equal(t, 1, lastWordsCount)
+	assert.Equal(t, 4, checkedCount)
+}
+
+func TestExecutionPhase15(t *testing.T) {
+	t.Log("追放フェーズ: 投票リクエストはすべてのエージェントに同時に送信される")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
 	var mu sync.Mutex
+	receivedCount := 0
+	allReceived := make(chan struct{})
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_DEAD,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
 
 	handlers := map[model.Request]func(tc TestClient) (string, error){
 		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
 			mu.Lock()
-			nameMap[tc.originalName] = tc.gameName
+			receivedCount++
+			if receivedCount == 5 {
+				close(allReceived)
+			}
 			mu.Unlock()
+			target := names.get("WEREWOLF")
+			select {
+			case <-allReceived:
+			case <-time.After(10 * time.Second):
+				t.Error("応答前にすべてのエージェントが投票リクエストを受信しませんでした")
+			}
+			return target, nil
+		},
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			return tc.validateStatusPattern(expectStatuses, names.snapshot())
+		},
+	}
+	executeGame(t, players, config, handlers)
+}
+
+func TestExecutionPhase16(t *testing.T) {
+	t.Log("追放フェーズ: 追放によりゲームが終了した場合、遺言はゲーム終了リクエストのトーク履歴に追加され、最大文字数を省略した場合は切り捨てられない")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.LastWords.Enable = true
+	config.Game.LastWords.MaxLength = 0
+
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+	var mu sync.Mutex
+	checkedCount := 0
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
 			return "", nil
 		},
 		model.R_VOTE: func(tc TestClient) (string, error) {
+			return names.get("WEREWOLF"), nil
+		},
+		model.R_LAST_WORDS: func(tc TestClient) (string, error) {
+			return "あとは任せました、村のみんな", nil
+		},
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			lastWords := make([]map[string]any, 0)
+			for _, talk := range tc.talkHistory {
+				if isLastWords, _ := talk.(map[string]any)["last_words"].(bool); isLastWords {
+					lastWords = append(lastWords, talk.(map[string]any))
+				}
+			}
+			if assert.Equal(t, 1, len(lastWords)) {
+				assert.Equal(t, names.get("WEREWOLF"), lastWords[0]["agent"])
+				assert.Equal(t, "あとは任せました、村のみんな", lastWords[0]["text"])
+			}
 			mu.Lock()
-			target := nameMap[targetMap[tc.originalName]]
+			checkedCount++
 			mu.Unlock()
+			return "", nil
+		},
+	}
+	executeGame(t, players, config, handlers)
+
+	assert.Equal(t, 5, checkedCount)
+}
+
+func TestExecutionPhase17(t *testing.T) {
+	t.Log("追放フェーズ: 同票時の処理がallの場合、追放された全員の霊能結果と追放結果の一覧が送信される")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Vote.TieBreak = "all"
+	config.Logic.Visibility.MediumResult = &[]string{"SEER"}
+
+	targetMap := map[string]string{
+		"WEREWOLF":   "POSSESSED",
+		"POSSESSED":  "VILLAGER-A",
+		"SEER":       "POSSESSED",
+		"VILLAGER-A": "WEREWOLF",
+		"VILLAGER-B": "VILLAGER-A",
+	}
+	executeTieBreakAll(t, config, targetMap, "", func(info map[string]any, names *nameRegistry) {
+		assert.ElementsMatch(t, []any{names.get("POSSESSED"), names.get("VILLAGER-A")}, info["executed_agents"])
+		results, ok := info["medium_results"].([]any)
+		if !assert.True(t, ok) {
+			return
+		}
+		targets := make([]any, 0)
+		for _, result := range results {
+			targets = append(targets, result.(map[string]any)["target"])
+		}
+		assert.ElementsMatch(t, []any{names.get("POSSESSED"), names.get("VILLAGER-A")}, targets)
+		assert.NotNil(t, info["medium_result"])
+	})
+}
+
+func TestExecutionPhase18(t *testing.T) {
+	t.Log("追放フェーズ: 同票時の処理がallの場合、対象が1人でも追放結果と襲撃結果の一覧が送信される")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Vote.TieBreak = "all"
+	config.Game.AttackVote.TieBreak = "all"
+	config.Logic.Visibility.MediumResult = &[]string{"SEER"}
+	config.Logic.NightPhases = []model.Phase{
+		{Name: "execution", Actions: []string{"execution"}},
+		{Name: "attack", Actions: []string{"attack"}},
+	}
+
+	targetMap := map[string]string{
+		"WEREWOLF":   "VILLAGER-A",
+		"POSSESSED":  "VILLAGER-A",
+		"SEER":       "VILLAGER-A",
+		"VILLAGER-A": "WEREWOLF",
+		"VILLAGER-B": "VILLAGER-A",
+	}
+	executeTieBreakAll(t, config, targetMap, "VILLAGER-B", func(info map[string]any, names *nameRegistry) {
+		assert.Equal(t, []any{names.get("VILLAGER-A")}, info["executed_agents"])
+		assert.Equal(t, []any{names.get("VILLAGER-B")}, info["attacked_agents"])
+		results, ok := info["medium_results"].([]any)
+		if assert.True(t, ok) {
+			assert.Equal(t, 1, len(results))
+		}
+	})
+}
+
+// executeTieBreakAll は初日に targetMap に従って投票するゲームを実行して、占い師がゲーム終了時に受信した前日の結果を検証します
+func executeTieBreakAll(t *testing.T, config *model.Config, targetMap map[string]string, attackTarget string, validate func(info map[string]any, names *nameRegistry)) {
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+	var mu sync.Mutex
+	var info map[string]any
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			target, _ := names.lookup(targetMap[tc.originalName])
+			return target, nil
+		},
+		model.R_ATTACK: func(tc TestClient) (string, error) {
+			target, _ := names.lookup(attackTarget)
+			return target, nil
+		},
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if tc.originalName == "SEER" {
+				info = tc.info
+			}
+			return "", nil
+		},
+	}
+	executeGame(t, players, config, handlers)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if info == nil {
+		t.Fatal("ゲーム終了時の情報を受信しませんでした")
+	}
+	validate(info, names)
+}
+
+func executeExecutionPhase(t *testing.T, targetMap map[string]string, expectStatuses []map[string]model.Status, config *model.Config) {
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			target, exists := names.lookup(targetMap[tc.originalName])
+			if !exists {
+				target = targetMap[tc.originalName]
+			}
+			tc.t.Logf("投票: %s -> %s", tc.gameName, target)
+			return target, nil
+		},
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			return tc.validateStatusPattern(expectStatuses, names.snapshot())
+		},
+	}
+	executeGame(t, players, config, handlers)
+}
+
+func executeRunoffPhase(t *testing.T, targetMap map[string]string, runoffTargetMap map[string]string, expectCandidates []string, expectStatuses []map[string]model.Status, config *model.Config) {
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			target := names.get(targetMap[tc.originalName])
+			if candidates, exists := tc.info["vote_candidates"].([]any); exists {
+				candidateNames := make([]string, 0)
+				for _, candidate := range expectCandidates {
+					candidateNames = append(candidateNames, names.get(candidate))
+				}
+				assert.ElementsMatch(t, candidateNames, candidates)
+				target = names.get(runoffTargetMap[tc.originalName])
+			}
 			tc.t.Logf("投票: %s -> %s", tc.gameName, target)
 			return target, nil
 		},
 		model.R_FINISH: func(tc TestClient) (string, error) {
-			return tc.validateStatusPattern(expectStatuses, nameMap)
+			return tc.validateStatusPattern(expectStatuses, names.snapshot())
 		},
 	}
-	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+	executeGame(t, players, config, handlers)
 }
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+)
+
+func TestFoxPhase1(t *testing.T) {
+	t.Log("妖狐: 人狼が妖狐を襲撃しても妖狐は死亡しない")
+	config, err := model.LoadFromPath("./config/fox.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	targetMap := map[model.Request]string{
+		model.R_DIVINE: "VILLAGER-A",
+		model.R_ATTACK: "FOX",
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"FOX":        model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeFoxPhase(t, targetMap, expectStatuses, config)
+}
+
+func TestFoxPhase2(t *testing.T) {
+	t.Log("妖狐: 占い師が妖狐を占うと妖狐が死亡する")
+	config, err := model.LoadFromPath("./config/fox.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	targetMap := map[model.Request]string{
+		model.R_DIVINE: "FOX",
+		model.R_ATTACK: "VILLAGER-A",
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"FOX":        model.S_DEAD,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_DEAD,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executeFoxPhase(t, targetMap, expectStatuses, config)
+}
+
+func executeFoxPhase(t *testing.T, targetMap map[model.Request]string, expectStatuses []map[string]model.Status, config *model.Config) {
+	players := []string{"WEREWOLF", "FOX", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+
+	handleTarget := func(request model.Request) func(tc TestClient) (string, error) {
+		return func(tc TestClient) (string, error) {
+			target := names.get(targetMap[request])
+			tc.t.Logf("%s: %s -> %s", request.String(), tc.gameName, target)
+			return target, nil
+		}
+	}
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_DIVINE: handleTarget(model.R_DIVINE),
+		model.R_ATTACK: handleTarget(model.R_ATTACK),
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			return tc.validateStatusPattern(expectStatuses, names.snapshot())
+		},
+	}
+	executeGame(t, players, config, handlers)
+}
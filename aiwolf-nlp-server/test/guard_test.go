@@ -0,0 +1,185 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardPhase1(t *testing.T) {
+	t.Log("護衛フェーズ: 護衛された村人は襲撃されても死亡しない")
+	config, err := model.LoadFromPath("./config/guard.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	targetMaps := []map[model.Request]string{
+		{
+			model.R_GUARD:  "VILLAGER-A",
+			model.R_ATTACK: "VILLAGER-A",
+		},
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"BODYGUARD":  model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+			"VILLAGER-C": model.S_ALIVE,
+		},
+	}
+	executeGuardPhase(t, targetMaps, expectStatuses, config, nil)
+}
+
+func TestGuardPhase2(t *testing.T) {
+	t.Log("護衛フェーズ: 自己護衛が許可されていない場合、自己護衛は無効になる")
+	config, err := model.LoadFromPath("./config/guard.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	targetMaps := []map[model.Request]string{
+		{
+			model.R_GUARD:  "BODYGUARD",
+			model.R_ATTACK: "BODYGUARD",
+		},
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"BODYGUARD":  model.S_DEAD,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+			"VILLAGER-C": model.S_ALIVE,
+		},
+	}
+	executeGuardPhase(t, targetMaps, expectStatuses, config, nil)
+}
+
+func TestGuardPhase3(t *testing.T) {
+	t.Log("護衛フェーズ: 自己護衛が許可されている場合、自己護衛が有効になる")
+	config, err := model.LoadFromPath("./config/guard.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Guard.AllowSelfGuard = true
+
+	targetMaps := []map[model.Request]string{
+		{
+			model.R_GUARD:  "BODYGUARD",
+			model.R_ATTACK: "BODYGUARD",
+		},
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"BODYGUARD":  model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+			"VILLAGER-C": model.S_ALIVE,
+		},
+	}
+	executeGuardPhase(t, targetMaps, expectStatuses, config, nil)
+}
+
+func TestGuardPhase4(t *testing.T) {
+	t.Log("護衛フェーズ: 連続護衛が禁止されている場合、前日と同じ対象の護衛は無効になる")
+	config, err := model.LoadFromPath("./config/guard.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.MaxDay = 1
+	config.Game.Guard.BanConsecutiveGuard = true
+
+	targetMaps := []map[model.Request]string{
+		{
+			model.R_GUARD:  "VILLAGER-A",
+			model.R_ATTACK: "VILLAGER-B",
+		},
+		{
+			model.R_GUARD:  "VILLAGER-A",
+			model.R_ATTACK: "VILLAGER-A",
+		},
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"BODYGUARD":  model.S_ALIVE,
+			"VILLAGER-A": model.S_DEAD,
+			"VILLAGER-B": model.S_DEAD,
+			"VILLAGER-C": model.S_ALIVE,
+		},
+	}
+	executeGuardPhase(t, targetMaps, expectStatuses, config, nil)
+}
+
+func TestGuardPhase5(t *testing.T) {
+	t.Log("護衛フェーズ: 護衛成功の公開が有効な場合、翌日に護衛された襲撃対象が通知される")
+	config, err := model.LoadFromPath("./config/guard.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.MaxDay = 1
+	config.Game.Guard.AnnounceGuardedAttack = true
+
+	targetMaps := []map[model.Request]string{
+		{
+			model.R_GUARD:  "VILLAGER-A",
+			model.R_ATTACK: "VILLAGER-A",
+		},
+		{
+			model.R_GUARD:  "VILLAGER-B",
+			model.R_ATTACK: "VILLAGER-C",
+		},
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"BODYGUARD":  model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+			"VILLAGER-C": model.S_DEAD,
+		},
+	}
+	executeGuardPhase(t, targetMaps, expectStatuses, config, map[int]string{1: "VILLAGER-A"})
+}
+
+func executeGuardPhase(t *testing.T, targetMaps []map[model.Request]string, expectStatuses []map[string]model.Status, config *model.Config, expectGuardedAgents map[int]string) {
+	players := []string{"WEREWOLF", "BODYGUARD", "VILLAGER-A", "VILLAGER-B", "VILLAGER-C"}
+	names := newNameRegistry(players)
+
+	handleTarget := func(request model.Request) func(tc TestClient) (string, error) {
+		return func(tc TestClient) (string, error) {
+			day := int(tc.info["day"].(float64))
+			target := names.get(targetMaps[day][request])
+			// 自分自身が対象の場合は、他のクライアントの登録を待たずに自身の情報から決める
+			if targetMaps[day][request] == tc.originalName {
+				target = tc.gameName
+			}
+			tc.t.Logf("%s: %s -> %s", request.String(), tc.gameName, target)
+			return target, nil
+		}
+	}
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_DAILY_INITIALIZE: func(tc TestClient) (string, error) {
+			day := int(tc.info["day"].(float64))
+			if name, exists := expectGuardedAgents[day]; exists {
+				assert.Equal(t, names.get(name), tc.info["guarded_agent"])
+			} else {
+				assert.NotContains(t, tc.info, "guarded_agent")
+			}
+			return "", nil
+		},
+		model.R_GUARD:  handleTarget(model.R_GUARD),
+		model.R_ATTACK: handleTarget(model.R_ATTACK),
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			return tc.validateStatusPattern(expectStatuses, names.snapshot())
+		},
+	}
+	executeGame(t, players, config, handlers)
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHello1(t *testing.T) {
+	t.Log("自己紹介: 自己紹介を送信したエージェントには、双方が対応している機能をHELLOリクエストで返す")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	hellos := make(map[string]map[string]any)
+	var mu sync.Mutex
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_NAME: func(tc TestClient) (string, error) {
+			return helloMessage(map[string]any{
+				"protocol":     model.ProtocolVersion,
+				"capabilities": []string{"structured_response", "compression"},
+				"language":     "ja",
+				"name":         tc.originalName,
+			}), nil
+		},
+		model.R_HELLO: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			hellos[tc.originalName] = tc.hello
+			return "", nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 5, len(hellos))
+	for name, hello := range hellos {
+		assert.Equal(t, float64(model.ProtocolVersion), hello["protocol"])
+		assert.Equal(t, []any{"structured_response"}, hello["capabilities"])
+		assert.Equal(t, name, hello["name"])
+	}
+}
+
+func TestHello2(t *testing.T) {
+	t.Log("自己紹介: 自己紹介が必須の場合、名前のみを返すエージェントは接続を拒否される")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Server.Handshake.RequireHello = true
+
+	err = executeHandshake(t, config, "legacy1")
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), err)
+}
+
+func TestHello3(t *testing.T) {
+	t.Log("自己紹介: 対応していないプロトコルのバージョンの場合、接続を拒否される")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	err = executeHandshake(t, config, helloMessage(map[string]any{
+		"protocol": model.ProtocolVersion + 1,
+		"name":     "future1",
+	}))
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), err)
+}
+
+func TestHello4(t *testing.T) {
+	t.Log("自己紹介: リアルタイム通信が有効な場合、リアルタイム通信に対応していないエージェントは接続を拒否される")
+	config, err := model.LoadFromPath("./config/realtime.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	err = executeHandshake(t, config, helloMessage(map[string]any{
+		"protocol":     model.ProtocolVersion,
+		"capabilities": []string{"structured_response"},
+		"name":         "turnbased1",
+	}))
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), err)
+}
+
+func helloMessage(hello map[string]any) string {
+	data, _ := json.Marshal(hello)
+	return string(data)
+}
+
+// executeHandshake はNAMEリクエストに reply で応答し、次に受信したメッセージの読み込みエラーを返します
+func executeHandshake(t *testing.T, config *model.Config, reply string) error {
+	u := launchAsyncServer(t, config)
+	time.Sleep(1 * time.Second)
+
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		t.Fatalf("接続に失敗しました: %v", err)
+	}
+	defer conn.Close()
+
+	if _, _, err := conn.ReadMessage(); err != nil {
+		t.Fatalf("NAMEリクエストの受信に失敗しました: %v", err)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(reply)); err != nil {
+		t.Fatalf("NAMEリクエストへの応答に失敗しました: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	return err
+}
@@ -0,0 +1,57 @@
+package test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasonTalkPhase1(t *testing.T) {
+	t.Log("共有会話フェーズ: 共有者同士が互いの役職を知り、共有会話を行う")
+	config, err := model.LoadFromPath("./config/mason.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	var mu sync.Mutex
+	masonTalks := 0
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			roleMap := tc.info["role_map"].(map[string]any)
+			if tc.role != model.R_MASON {
+				assert.Equal(t, 1, len(roleMap))
+				return "", nil
+			}
+			assert.Equal(t, 2, len(roleMap))
+			for _, role := range roleMap {
+				assert.Equal(t, model.R_MASON.String(), role)
+			}
+			return "", nil
+		},
+		model.R_MASON_TALK: func(tc TestClient) (string, error) {
+			assert.Equal(t, model.R_MASON, tc.role)
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, masonTalks, len(tc.masonTalkHistory))
+			masonTalks++
+			if masonTalks > 2 {
+				return model.T_OVER, nil
+			}
+			return "Hello Mason!", nil
+		},
+		model.R_DAILY_FINISH: func(tc TestClient) (string, error) {
+			if tc.role == model.R_MASON {
+				mu.Lock()
+				assert.Equal(t, masonTalks, len(tc.masonTalkHistory))
+				mu.Unlock()
+			} else {
+				assert.Equal(t, 0, len(tc.masonTalkHistory))
+			}
+			return "", nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "MASON-A", "MASON-B", "SEER", "VILLAGER"}, config, handlers)
+}
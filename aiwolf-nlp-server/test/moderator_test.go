@@ -0,0 +1,96 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModerator1(t *testing.T) {
+	t.Log("モデレーター: 沈黙しているエージェントに発言を促し、促せなくなってからフェーズを終了する")
+	config, err := model.LoadFromPath("./config/realtime.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	executeModerator(t, config, nil, func(reasonsMap map[string][]string, talkCountMap map[string]int, nameMap map[string]string) {
+		for name := range nameMap {
+			assert.Equal(t, []string{model.PR_SILENCE.String(), model.PR_SILENCE.String()}, reasonsMap[name])
+			assert.Equal(t, 5, talkCountMap[name])
+		}
+	})
+}
+
+func TestModerator2(t *testing.T) {
+	t.Log("モデレーター: メンションされたエージェントに発言を促す")
+	config, err := model.LoadFromPath("./config/realtime.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	message := func(tc TestClient, names *nameRegistry) string {
+		if tc.originalName == "WEREWOLF" {
+			return fmt.Sprintf("@%s どう思う?", names.get("VILLAGER-A"))
+		}
+		return "Hello World!"
+	}
+	executeModerator(t, config, message, func(reasonsMap map[string][]string, talkCountMap map[string]int, nameMap map[string]string) {
+		assert.Equal(t, []string{model.PR_SILENCE.String(), model.PR_MENTION.String()}, reasonsMap["VILLAGER-A"])
+	})
+}
+
+func TestModerator3(t *testing.T) {
+	t.Log("モデレーター: モデレーターが無効な場合は発言を促さない")
+	config, err := model.LoadFromPath("./config/realtime.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Realtime.Moderator.Enable = false
+
+	executeModerator(t, config, nil, func(reasonsMap map[string][]string, talkCountMap map[string]int, nameMap map[string]string) {
+		assert.Equal(t, 0, len(reasonsMap))
+		assert.Equal(t, 0, len(talkCountMap))
+	})
+}
+
+func executeModerator(t *testing.T, config *model.Config, message func(tc TestClient, names *nameRegistry) string, validate func(reasonsMap map[string][]string, talkCountMap map[string]int, nameMap map[string]string)) {
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+	reasonsMap := make(map[string][]string)
+	talkCountMap := make(map[string]int)
+	var mu sync.Mutex
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_TALK_PROMPT: func(tc TestClient) (string, error) {
+			mu.Lock()
+			reasonsMap[tc.originalName] = append(reasonsMap[tc.originalName], tc.promptReason)
+			promptCount := len(reasonsMap[tc.originalName])
+			mu.Unlock()
+			if promptCount > 1 {
+				return model.T_OVER, nil
+			}
+			if message != nil {
+				return message(tc, names), nil
+			}
+			return "Hello World!", nil
+		},
+		model.R_TALK_BROADCAST: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			talkCountMap[tc.originalName]++
+			return "", nil
+		},
+	}
+	executeGame(t, players, config, handlers)
+
+	mu.Lock()
+	defer mu.Unlock()
+	validate(reasonsMap, talkCountMap, names.snapshot())
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/logic"
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// nightWatchCollect は同時に実行できるアクション night_watch のリクエストを送信する時に呼び出されます
+var nightWatchCollect func()
+
+func init() {
+	err := logic.RegisterAction("night_watch", logic.SimultaneousActionFunc{
+		Single: func(g *logic.Game) {},
+		Parallel: func(g *logic.Game) func() {
+			if nightWatchCollect != nil {
+				nightWatchCollect()
+			}
+			return func() {}
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestNightAction1(t *testing.T) {
+	t.Log("夜のアクション: 同時実行が有効な場合、護衛と襲撃のリクエストが並列に送信される")
+	config, err := model.LoadFromPath("./config/guard.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.NightAction.Simultaneous = true
+	config.Logic.NightPhases = []model.Phase{
+		{Name: "night", Actions: []string{"guard", "attack"}},
+	}
+
+	attackReceived := make(chan struct{})
+	var once sync.Once
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"BODYGUARD":  model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+			"VILLAGER-C": model.S_ALIVE,
+		},
+	}
+	executeNightAction(t, "VILLAGER-A", "VILLAGER-A", expectStatuses, config, func() {
+		select {
+		case <-attackReceived:
+		case <-time.After(10 * time.Second):
+			t.Error("護衛リクエストの応答前に襲撃リクエストを受信しませんでした")
+		}
+	}, func() {
+		once.Do(func() { close(attackReceived) })
+	})
+}
+
+func TestNightAction2(t *testing.T) {
+	t.Log("夜のアクション: 襲撃を護衛より先に解決する場合、護衛は襲撃に間に合わない")
+	config, err := model.LoadFromPath("./config/guard.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.NightAction.Simultaneous = true
+	config.Game.NightAction.ResolutionOrder = []string{"attack", "guard"}
+	config.Logic.NightPhases = []model.Phase{
+		{Name: "night", Actions: []string{"guard", "attack"}},
+	}
+
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"BODYGUARD":  model.S_ALIVE,
+			"VILLAGER-A": model.S_DEAD,
+			"VILLAGER-B": model.S_ALIVE,
+			"VILLAGER-C": model.S_ALIVE,
+		},
+	}
+	executeNightAction(t, "VILLAGER-A", "VILLAGER-A", expectStatuses, config, nil, nil)
+}
+
+func TestNightAction3(t *testing.T) {
+	t.Log("夜のアクション: 解決順序に同時に実行できないアクションを含む場合はエラーになる")
+	config, err := model.LoadFromPath("./config/guard.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	assert.NoError(t, logic.ValidateConfig(*config))
+
+	config.Game.NightAction.ResolutionOrder = []string{"guard", "execution"}
+	assert.Error(t, logic.ValidateConfig(*config))
+}
+
+func TestNightAction4(t *testing.T) {
+	t.Log("夜のアクション: 同時に実行できるアクションとして登録したアクションは、襲撃と並列に実行される")
+	config, err := model.LoadFromPath("./config/guard.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.NightAction.Simultaneous = true
+	config.Game.NightAction.ResolutionOrder = []string{"night_watch", "attack"}
+	config.Logic.NightPhases = []model.Phase{
+		{Name: "night", Actions: []string{"night_watch", "attack"}},
+	}
+	assert.NoError(t, logic.ValidateConfig(*config))
+
+	attackReceived := make(chan struct{})
+	var once sync.Once
+	nightWatchCollect = func() {
+		select {
+		case <-attackReceived:
+		case <-time.After(10 * time.Second):
+			t.Error("night_watchの終了前に襲撃リクエストを受信しませんでした")
+		}
+	}
+	defer func() { nightWatchCollect = nil }()
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"BODYGUARD":  model.S_ALIVE,
+			"VILLAGER-A": model.S_DEAD,
+			"VILLAGER-B": model.S_ALIVE,
+			"VILLAGER-C": model.S_ALIVE,
+		},
+	}
+	executeNightAction(t, "VILLAGER-B", "VILLAGER-A", expectStatuses, config, nil, func() {
+		once.Do(func() { close(attackReceived) })
+	})
+}
+
+func executeNightAction(t *testing.T, guardTarget string, attackTarget string, expectStatuses []map[string]model.Status, config *model.Config, onGuard func(), onAttack func()) {
+	players := []string{"WEREWOLF", "BODYGUARD", "VILLAGER-A", "VILLAGER-B", "VILLAGER-C"}
+	names := newNameRegistry(players)
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_GUARD: func(tc TestClient) (string, error) {
+			if onGuard != nil {
+				onGuard()
+			}
+			return names.get(guardTarget), nil
+		},
+		model.R_ATTACK: func(tc TestClient) (string, error) {
+			if onAttack != nil {
+				onAttack()
+			}
+			return names.get(attackTarget), nil
+		},
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			return tc.validateStatusPattern(expectStatuses, names.snapshot())
+		},
+	}
+	executeGame(t, players, config, handlers)
+}
@@ -0,0 +1,157 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutbound1(t *testing.T) {
+	t.Log("送信キュー: レスポンスを待つリクエストは送信キューが小さくてもポリシーが適用されず、ゲームが最後まで進む")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Server.Outbound.QueueSize = 1
+	config.Server.Outbound.FullPolicy = "disconnect"
+
+	finished := make(map[string]bool)
+	var mu sync.Mutex
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			finished[tc.originalName] = true
+			return "", nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+	assert.Equal(t, 5, len(finished))
+}
+
+func TestOutbound2(t *testing.T) {
+	t.Log("送信キュー: 不明なポリシーを指定した場合はエラーになる")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Server.Outbound.FullPolicy = "block"
+
+	_, err = model.NewSetting(*config)
+	assert.Error(t, err)
+}
+
+func TestOutbound3(t *testing.T) {
+	t.Log("送信キュー: drop_oldest の場合、受信が止まったエージェントへのパケットは古いものから破棄され、エラーにならない")
+	agent, _ := launchStalledAgent(t, model.QP_DROP_OLDEST)
+
+	err := fillOutbound(agent, func(stats *model.OutboundStats) bool {
+		return stats.Dropped > 0
+	})
+	assert.NoError(t, err)
+	stats := agent.OutboundStats()
+	assert.Equal(t, stallQueueSize, stats.Depth)
+	assert.Equal(t, stallQueueSize, stats.MaxDepth)
+	assert.Greater(t, stats.Dropped, 0)
+	assert.False(t, agent.HasError())
+}
+
+func TestOutbound4(t *testing.T) {
+	t.Log("送信キュー: mark_error の場合、受信が止まったエージェントはエラー状態になり、以降の送信が失敗する")
+	agent, _ := launchStalledAgent(t, model.QP_MARK_ERROR)
+
+	err := fillOutbound(agent, func(stats *model.OutboundStats) bool {
+		return false
+	})
+	assert.Error(t, err)
+	stats := agent.OutboundStats()
+	assert.Equal(t, stallQueueSize, stats.MaxDepth)
+	assert.Equal(t, 0, stats.Dropped)
+	assert.True(t, agent.HasError())
+	assert.Error(t, agent.SendNonBlocking(stallPacket()))
+}
+
+func TestOutbound5(t *testing.T) {
+	t.Log("送信キュー: disconnect の場合、受信が止まったエージェントはエラー状態になり、接続が切断される")
+	agent, peer := launchStalledAgent(t, model.QP_DISCONNECT)
+
+	err := fillOutbound(agent, func(stats *model.OutboundStats) bool {
+		return false
+	})
+	assert.Error(t, err)
+	stats := agent.OutboundStats()
+	assert.Equal(t, stallQueueSize, stats.MaxDepth)
+	assert.True(t, agent.HasError())
+
+	// 受信を再開すると、送信済みのパケットの後に切断が検知される
+	peer.SetReadDeadline(time.Now().Add(10 * time.Second))
+	for {
+		if _, _, err = peer.ReadMessage(); err != nil {
+			break
+		}
+	}
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseAbnormalClosure), err)
+}
+
+// 受信が止まったエージェントの送信キューのサイズ
+const stallQueueSize = 4
+
+// launchStalledAgent は受信を行わない相手に接続したエージェントを作成し、送信キューを開始します
+// 相手側の接続も返します
+func launchStalledAgent(t *testing.T, policy model.QueuePolicy) (*model.Agent, *websocket.Conn) {
+	peers := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("接続のアップグレードに失敗しました: %v", err)
+			return
+		}
+		peers <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("接続に失敗しました: %v", err)
+	}
+	peer := <-peers
+	t.Cleanup(func() { peer.Close() })
+
+	agent := model.NewAgent(0, model.R_VILLAGER, model.Connection{Conn: conn})
+	agent.StartOutbound(model.OutboundSetting{
+		QueueSize:    stallQueueSize,
+		WriteTimeout: 30 * time.Second,
+		FullPolicy:   policy,
+	})
+	t.Cleanup(agent.Close)
+	return agent, peer
+}
+
+// fillOutbound はソケットのバッファが埋まるまで大きなパケットを送信し続けます
+// done が真を返すか送信が失敗した時点で終了し、送信のエラーを返します
+func fillOutbound(agent *model.Agent, done func(stats *model.OutboundStats) bool) error {
+	for range 1000 {
+		if err := agent.SendNonBlocking(stallPacket()); err != nil {
+			return err
+		}
+		if done(agent.OutboundStats()) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func stallPacket() model.Packet {
+	talks := []model.Talk{{Text: strings.Repeat("a", 1<<20)}}
+	return model.Packet{
+		Request:     &model.R_TALK,
+		TalkHistory: &talks,
+	}
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type remainingTimeNotice struct {
+	deadline      time.Time
+	remainingTime int
+}
+
+func TestPhaseClock1(t *testing.T) {
+	t.Log("フェーズ時間: 発言が続いている間は上限まで締め切りを延長し、残り時間を通知する")
+	config := loadPhaseClockConfig(t)
+	config.Game.Realtime.Adaptive = model.AdaptiveConfig{
+		Enable:       true,
+		ActiveWindow: 1 * time.Second,
+		Extension:    1 * time.Second,
+		MaxDuration:  4 * time.Second,
+	}
+
+	executePhaseClock(t, config, func(startDeadline time.Time, startLead time.Duration, notices []remainingTimeNotice) {
+		assert.InDelta(t, float64(2*time.Second), float64(startLead), float64(500*time.Millisecond))
+
+		// 延長前の締め切りに対して、残り時間の閾値で通知される
+		thresholdNotified := false
+		for _, notice := range notices {
+			if notice.deadline.Equal(startDeadline) && notice.remainingTime <= 1000 {
+				thresholdNotified = true
+			}
+		}
+		assert.True(t, thresholdNotified)
+
+		// 延長のたびに通知され、締め切りは最大時間を超えない
+		latest := startDeadline
+		for _, notice := range notices {
+			if notice.deadline.After(latest) {
+				latest = notice.deadline
+			}
+		}
+		assert.Equal(t, startDeadline.Add(2*time.Second), latest)
+	})
+}
+
+func TestPhaseClock2(t *testing.T) {
+	t.Log("フェーズ時間: 適応的なフェーズ時間が無効な場合は締め切りを延長しない")
+	config := loadPhaseClockConfig(t)
+
+	executePhaseClock(t, config, func(startDeadline time.Time, startLead time.Duration, notices []remainingTimeNotice) {
+		assert.InDelta(t, float64(2*time.Second), float64(startLead), float64(500*time.Millisecond))
+		assert.NotEmpty(t, notices)
+		for _, notice := range notices {
+			assert.True(t, notice.deadline.Equal(startDeadline))
+			assert.LessOrEqual(t, notice.remainingTime, 1000)
+		}
+	})
+}
+
+// loadPhaseClockConfig はモデレーターの催促で発言が途切れないリアルタイム通信の設定を読み込みます
+func loadPhaseClockConfig(t *testing.T) *model.Config {
+	config, err := model.LoadFromPath("./config/realtime.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Talk.MaxCount.PerAgent = 20
+	config.Game.Talk.MaxCount.PerDay = 100
+	config.Game.Realtime.PhaseTimeout = 2 * time.Second
+	config.Game.Realtime.SilenceTimeout = 10 * time.Second
+	config.Game.Realtime.Moderator.NudgeAfter = 500 * time.Millisecond
+	config.Game.Realtime.Moderator.MaxNudges = 20
+	config.Game.Realtime.RemainingTimeNotices = []time.Duration{1 * time.Second}
+	return config
+}
+
+func executePhaseClock(t *testing.T, config *model.Config, validate func(startDeadline time.Time, startLead time.Duration, notices []remainingTimeNotice)) {
+	var mu sync.Mutex
+	var startDeadline time.Time
+	var startLead time.Duration
+	var notices []remainingTimeNotice
+
+	isFirstDay := func(tc TestClient) bool {
+		day, ok := tc.info["day"].(float64)
+		return ok && day == 0
+	}
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_TALK_START: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if isFirstDay(tc) && tc.originalName == "SEER" {
+				startDeadline = tc.deadline
+				startLead = time.Until(tc.deadline)
+			}
+			return "", nil
+		},
+		model.R_TALK_REMAINING_TIME: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if isFirstDay(tc) && tc.originalName == "SEER" {
+				notices = append(notices, remainingTimeNotice{deadline: tc.deadline, remainingTime: tc.remainingTime})
+			}
+			return "", nil
+		},
+		model.R_TALK_PROMPT: func(tc TestClient) (string, error) {
+			return "Hello World!", nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+
+	mu.Lock()
+	defer mu.Unlock()
+	validate(startDeadline, startLead, notices)
+}
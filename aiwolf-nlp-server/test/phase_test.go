@@ -0,0 +1,227 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhaseCondition1(t *testing.T) {
+	t.Log("フェーズ条件: 指定した役職が生存していない場合、フェーズはスキップされる")
+	config, err := model.LoadFromPath("./config/phase.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	role := "SEER"
+	config.Logic.NightPhases[0].RoleAlive = &role
+
+	targets := []string{"SEER", "VILLAGER-A"}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_DEAD,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executePhaseCondition(t, targets, expectStatuses, config)
+}
+
+func TestPhaseCondition2(t *testing.T) {
+	t.Log("フェーズ条件: N日ごとの実行対象の日ではない場合、フェーズはスキップされる")
+	config, err := model.LoadFromPath("./config/phase.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	everyNDays := 2
+	config.Logic.NightPhases[0].EveryNDays = &everyNDays
+
+	targets := []string{"VILLAGER-A", "VILLAGER-B"}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_DEAD,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executePhaseCondition(t, targets, expectStatuses, config)
+}
+
+func TestPhaseCondition3(t *testing.T) {
+	t.Log("フェーズ条件: 日の範囲外の場合、フェーズはスキップされる")
+	config, err := model.LoadFromPath("./config/phase.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	minDay := 1
+	config.Logic.NightPhases[0].DayRange = &model.DayRange{Min: &minDay}
+
+	targets := []string{"VILLAGER-A", "VILLAGER-B"}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_DEAD,
+		},
+	}
+	executePhaseCondition(t, targets, expectStatuses, config)
+}
+
+func TestPhaseCondition4(t *testing.T) {
+	t.Log("フェーズ条件: 生存者数が最小生存者数を下回る場合、フェーズはスキップされる")
+	config, err := model.LoadFromPath("./config/phase.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	minAlive := 5
+	config.Logic.NightPhases[0].MinAlive = &minAlive
+
+	targets := []string{"VILLAGER-A", "VILLAGER-B"}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_DEAD,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executePhaseCondition(t, targets, expectStatuses, config)
+}
+
+func TestPhaseCondition5(t *testing.T) {
+	t.Log("フェーズ条件: 昨夜の死亡者がいる場合、死亡者がいない場合にスキップするフェーズは実行される")
+	config, err := model.LoadFromPath("./config/phase.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	onlyDay := 0
+	exceptDay := 0
+	config.Logic.NightPhases = []model.Phase{
+		{Name: "first_execution", Actions: []string{"execution"}, OnlyDay: &onlyDay},
+		{Name: "execution", Actions: []string{"execution"}, ExceptDay: &exceptDay, SkipIfNoDeath: true},
+	}
+
+	targets := []string{"VILLAGER-A", "VILLAGER-B"}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_DEAD,
+			"VILLAGER-B": model.S_DEAD,
+		},
+	}
+	executePhaseCondition(t, targets, expectStatuses, config)
+}
+
+func TestPhaseCondition6(t *testing.T) {
+	t.Log("フェーズ条件: 昨夜の死亡者がいない場合、死亡者がいない場合にスキップするフェーズはスキップされる")
+	config, err := model.LoadFromPath("./config/phase.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	onlyDay := 0
+	exceptDay := 0
+	role := "MEDIUM"
+	config.Logic.NightPhases = []model.Phase{
+		{Name: "first_execution", Actions: []string{"execution"}, OnlyDay: &onlyDay, RoleAlive: &role},
+		{Name: "execution", Actions: []string{"execution"}, ExceptDay: &exceptDay, SkipIfNoDeath: true},
+	}
+
+	targets := []string{"VILLAGER-A", "VILLAGER-B"}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_ALIVE,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	executePhaseCondition(t, targets, expectStatuses, config)
+}
+
+func TestPhaseCondition7(t *testing.T) {
+	t.Log("フェーズ条件: N日ごとの指定が0以下の場合はエラーになる")
+	config, err := model.LoadFromPath("./config/phase.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	everyNDays := 0
+	config.Logic.NightPhases[0].EveryNDays = &everyNDays
+
+	_, err = model.NewSetting(*config)
+	assert.Error(t, err)
+}
+
+func TestPhaseSkipLog(t *testing.T) {
+	t.Log("フェーズ条件: スキップしたフェーズは理由とともにゲームログに記録される")
+	config, err := model.LoadFromPath("./config/phase.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	everyNDays := 2
+	config.Logic.NightPhases[0].EveryNDays = &everyNDays
+
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	targets := []string{"VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+	var mu sync.Mutex
+	var gameID string
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			mu.Lock()
+			gameID = tc.info["game_id"].(string)
+			mu.Unlock()
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			day := int(tc.info["day"].(float64))
+			return names.get(targets[day]), nil
+		},
+	}
+	executeGame(t, players, config, handlers)
+
+	mu.Lock()
+	defer mu.Unlock()
+	data, err := os.ReadFile(filepath.Join(config.GameLogger.OutputDir, gameID+".log"))
+	if err != nil {
+		t.Fatalf("ゲームログの読み込みに失敗しました: %v", err)
+	}
+	assert.Contains(t, string(data), "1,skipPhase,execution,2日ごとの実行対象の日ではありません")
+	assert.NotContains(t, string(data), "0,skipPhase,")
+}
+
+func executePhaseCondition(t *testing.T, targets []string, expectStatuses []map[string]model.Status, config *model.Config) {
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			day := int(tc.info["day"].(float64))
+			target := names.get(targets[day])
+			tc.t.Logf("投票: %s -> %s", tc.gameName, target)
+			return target, nil
+		},
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			return tc.validateStatusPattern(expectStatuses, names.snapshot())
+		},
+	}
+	executeGame(t, players, config, handlers)
+}
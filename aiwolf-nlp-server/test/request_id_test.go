@@ -0,0 +1,90 @@
+package test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID1(t *testing.T) {
+	t.Log("リクエストID: リクエストIDを付けたJSON形式のレスポンスのテキストを発言として扱う")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	executeRequestID(t, config, func(tc TestClient, messageIdx int) string {
+		return structuredResponse(map[string]any{"request_id": tc.requestID, "text": "Hello World!"})
+	}, func(texts map[string][]string, requestIDs map[string]bool) {
+		for name, agentTexts := range texts {
+			assert.Contains(t, agentTexts, "Hello World!", name)
+		}
+		assert.NotContains(t, requestIDs, "")
+	})
+}
+
+func TestRequestID2(t *testing.T) {
+	t.Log("リクエストID: リクエストIDが一致しないレスポンスは破棄され、発言として扱わない")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Server.Timeout.Action = 1 * time.Second
+	config.Server.Timeout.Acceptable = 0
+
+	executeRequestID(t, config, func(tc TestClient, messageIdx int) string {
+		if tc.originalName == "SEER" && messageIdx == 0 {
+			return structuredResponse(map[string]any{"request_id": "mismatched", "text": "Mismatched"})
+		}
+		return "Hello World!"
+	}, func(texts map[string][]string, requestIDs map[string]bool) {
+		for name, agentTexts := range texts {
+			assert.NotContains(t, agentTexts, "Mismatched", name)
+			assert.Contains(t, agentTexts, "Hello World!", name)
+		}
+	})
+}
+
+func executeRequestID(t *testing.T, config *model.Config, message func(tc TestClient, messageIdx int) string, validate func(texts map[string][]string, requestIDs map[string]bool)) {
+	messageIdxMap := make(map[string]int)
+	requestIDs := make(map[string]bool)
+	texts := make(map[string][]string)
+	var mu sync.Mutex
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_TALK: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.False(t, requestIDs[tc.requestID], "リクエストIDが重複しています")
+			requestIDs[tc.requestID] = true
+			messageIdx := messageIdxMap[tc.originalName]
+			messageIdxMap[tc.originalName]++
+			if messageIdx >= 2 {
+				return model.T_OVER, nil
+			}
+			return message(tc, messageIdx), nil
+		},
+		model.R_DAILY_FINISH: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(texts) > 0 {
+				return "", nil
+			}
+			for _, talk := range tc.talkHistory {
+				talk := talk.(map[string]any)
+				agent := talk["agent"].(string)
+				texts[agent] = append(texts[agent], talk["text"].(string))
+			}
+			return "", nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 5, len(texts))
+	validate(texts, requestIDs)
+}
@@ -0,0 +1,293 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStructuredResponse1(t *testing.T) {
+	t.Log("構造化されたレスポンス: targetを投票先として扱い、理由をJSONログに記録し、トークン数をコスト集計に含める")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	targetMap := map[string]string{
+		"WEREWOLF":   "VILLAGER-B",
+		"POSSESSED":  "WEREWOLF",
+		"SEER":       "WEREWOLF",
+		"VILLAGER-A": "WEREWOLF",
+		"VILLAGER-B": "WEREWOLF",
+	}
+	expectStatuses := []map[string]model.Status{
+		{
+			"WEREWOLF":   model.S_DEAD,
+			"POSSESSED":  model.S_ALIVE,
+			"SEER":       model.S_ALIVE,
+			"VILLAGER-A": model.S_ALIVE,
+			"VILLAGER-B": model.S_ALIVE,
+		},
+	}
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+	var gameID string
+	var addr string
+	var costs []any
+	var mu sync.Mutex
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_NAME: structuredHello,
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			mu.Lock()
+			defer mu.Unlock()
+			gameID = tc.info["game_id"].(string)
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			target := names.get(targetMap[tc.originalName])
+			return structuredResponse(map[string]any{
+				"request_id": tc.requestID,
+				"target":     target,
+				"rationale":  "Rationale of " + tc.originalName,
+				"usage":      map[string]int{"input_tokens": 100, "output_tokens": 10},
+				"model":      "test-model",
+			}), nil
+		},
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if costs == nil {
+				res, err := http.Get("http://" + tc.conn.RemoteAddr().String() + "/api/status")
+				if err != nil {
+					return "", err
+				}
+				defer res.Body.Close()
+				var status map[string]any
+				if err := json.NewDecoder(res.Body).Decode(&status); err != nil {
+					return "", err
+				}
+				costs = status["costs"].([]any)
+				addr = tc.conn.RemoteAddr().String()
+			}
+			return tc.validateStatusPattern(expectStatuses, names.snapshot())
+		},
+	}
+	executeGame(t, players, config, handlers)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, len(costs))
+	summary := costs[0].(map[string]any)
+	assert.Equal(t, gameID, summary["game_id"])
+	assert.Equal(t, 5, len(summary["agents"].([]any)))
+	assert.Equal(t, float64(500), summary["total_input_tokens"])
+	assert.Equal(t, float64(50), summary["total_output_tokens"])
+	for _, agent := range summary["agents"].([]any) {
+		assert.Equal(t, "test-model", agent.(map[string]any)["model"])
+		assert.Equal(t, float64(1), agent.(map[string]any)["call_count"])
+	}
+
+	data, err := os.ReadFile(filepath.Join(config.JSONLogger.OutputDir, gameID+".json"))
+	if err != nil {
+		t.Fatalf("JSONログの読み込みに失敗しました: %v", err)
+	}
+	for name := range targetMap {
+		assert.Contains(t, string(data), "Rationale of "+name)
+	}
+
+	// ゲーム終了後に送信されたコストレポートは、トークン使用量から集計したコストレポートを置き換える
+	report, _ := json.Marshal(map[string]any{
+		"game_id":       gameID,
+		"agent":         names.get("SEER"),
+		"model":         "posted-model",
+		"input_tokens":  1000,
+		"output_tokens": 100,
+		"total_cost":    0.5,
+		"call_count":    1,
+	})
+	res, err := http.Post("http://"+addr+"/api/cost/report", "application/json", bytes.NewReader(report))
+	if err != nil {
+		t.Fatalf("コストレポートの送信に失敗しました: %v", err)
+	}
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = http.Get("http://" + addr + "/api/status")
+	if err != nil {
+		t.Fatalf("ステータスの取得に失敗しました: %v", err)
+	}
+	defer res.Body.Close()
+	var status map[string]any
+	if err := json.NewDecoder(res.Body).Decode(&status); err != nil {
+		t.Fatalf("ステータスのパースに失敗しました: %v", err)
+	}
+	summary = status["costs"].([]any)[0].(map[string]any)
+	assert.Equal(t, 5, len(summary["agents"].([]any)))
+	assert.Equal(t, float64(1400), summary["total_input_tokens"])
+	assert.Equal(t, float64(0.5), summary["total_cost"])
+}
+
+func TestStructuredResponse2(t *testing.T) {
+	t.Log("構造化されたレスポンス: 理由は他のエージェントに共有されない")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	messageIdxMap := make(map[string]int)
+	var mu sync.Mutex
+	var histories []string
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_NAME: structuredHello,
+		model.R_TALK: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, talk := range tc.talkHistory {
+				histories = append(histories, talk.(map[string]any)["text"].(string))
+			}
+			messageIdx := messageIdxMap[tc.originalName]
+			messageIdxMap[tc.originalName]++
+			if messageIdx >= 2 {
+				return model.T_OVER, nil
+			}
+			return structuredResponse(map[string]any{
+				"text":      "Hello World!",
+				"rationale": "Secret",
+			}), nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, histories, "Hello World!")
+	for _, text := range histories {
+		assert.False(t, strings.Contains(text, "Secret"), text)
+	}
+}
+
+func TestStructuredResponse3(t *testing.T) {
+	t.Log("構造化されたレスポンス: リアルタイム通信の発言の理由とモデルをJSONログに記録する")
+	config, err := model.LoadFromPath("./config/realtime.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	var gameID string
+	promptedMap := make(map[string]bool)
+	var mu sync.Mutex
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_NAME: structuredHello,
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			gameID = tc.info["game_id"].(string)
+			return "", nil
+		},
+		model.R_TALK_PROMPT: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if promptedMap[tc.originalName] {
+				return model.T_OVER, nil
+			}
+			promptedMap[tc.originalName] = true
+			return structuredResponse(map[string]any{
+				"text":      "Hello World!",
+				"rationale": "Realtime rationale of " + tc.originalName,
+				"model":     "realtime-model",
+			}), nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+
+	mu.Lock()
+	defer mu.Unlock()
+	data, err := os.ReadFile(filepath.Join(config.JSONLogger.OutputDir, gameID+".json"))
+	if err != nil {
+		t.Fatalf("JSONログの読み込みに失敗しました: %v", err)
+	}
+	for name := range promptedMap {
+		assert.Contains(t, string(data), "Realtime rationale of "+name)
+	}
+	assert.Contains(t, string(data), "realtime-model")
+	assert.Equal(t, 5, len(promptedMap))
+}
+
+func TestStructuredResponse4(t *testing.T) {
+	t.Log("構造化されたレスポンス: structured_responseをネゴシエーションしていないエージェントも、リクエストIDとテキストは受け付け、理由は記録しない")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	var gameID string
+	messageIdxMap := make(map[string]int)
+	var mu sync.Mutex
+	var histories []string
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			gameID = tc.info["game_id"].(string)
+			return "", nil
+		},
+		model.R_TALK: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, talk := range tc.talkHistory {
+				histories = append(histories, talk.(map[string]any)["text"].(string))
+			}
+			messageIdx := messageIdxMap[tc.originalName]
+			messageIdxMap[tc.originalName]++
+			if messageIdx >= 2 {
+				return model.T_OVER, nil
+			}
+			return structuredResponse(map[string]any{
+				"request_id": tc.requestID,
+				"text":       "Hello World!",
+				"rationale":  "Unnegotiated rationale",
+			}), nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, histories, "Hello World!")
+	for _, text := range histories {
+		assert.False(t, strings.Contains(text, "request_id"), text)
+	}
+
+	data, err := os.ReadFile(filepath.Join(config.JSONLogger.OutputDir, gameID+".json"))
+	if err != nil {
+		t.Fatalf("JSONログの読み込みに失敗しました: %v", err)
+	}
+	assert.NotContains(t, string(data), "Unnegotiated rationale")
+}
+
+// structuredHello は構造化されたレスポンスに対応する自己紹介でNAMEリクエストに応答します
+func structuredHello(tc TestClient) (string, error) {
+	return helloMessage(map[string]any{
+		"protocol":     model.ProtocolVersion,
+		"capabilities": []string{"realtime", "structured_response"},
+		"name":         tc.originalName,
+	}), nil
+}
+
+func structuredResponse(response map[string]any) string {
+	data, _ := json.Marshal(response)
+	return string(data)
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult1(t *testing.T) {
+	t.Log("結果: 最大日数に達した場合、デフォルトでは引き分けになる")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	executeResult(t, "VILLAGER-B", model.T_NONE, model.RR_MAX_DAY, config)
+}
+
+func TestResult2(t *testing.T) {
+	t.Log("結果: 最大日数に達した場合、設定に応じて人狼陣営の勝利になる")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.MaxDayOutcome = "werewolf"
+
+	executeResult(t, "VILLAGER-B", model.T_WEREWOLF, model.RR_MAX_DAY, config)
+}
+
+func TestResult3(t *testing.T) {
+	t.Log("結果: 最大日数に達した場合、生存者数が多い陣営の勝利になる")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.MaxDayOutcome = "survivors"
+
+	executeResult(t, "POSSESSED", model.T_VILLAGER, model.RR_MAX_DAY, config)
+}
+
+func TestResult4(t *testing.T) {
+	t.Log("結果: 最大日数に達した場合、生存者数が同数の陣営同士では引き分けになる")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.MaxDayOutcome = "survivors"
+
+	executeResult(t, "VILLAGER-B", model.T_NONE, model.RR_MAX_DAY, config)
+}
+
+func TestResult5(t *testing.T) {
+	t.Log("結果: 最大日数に達しても勝利条件を満たしている場合は、その勝利理由が優先される")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.MaxDayOutcome = "werewolf"
+
+	executeResult(t, "WEREWOLF", model.T_VILLAGER, model.RR_ELIMINATION, config)
+}
+
+func TestResult6(t *testing.T) {
+	t.Log("結果: 不明な最大日数到達時の結果はエラーになる")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.MaxDayOutcome = "fox"
+
+	_, err = model.NewSetting(*config)
+	assert.Error(t, err)
+}
+
+func executeResult(t *testing.T, target string, expectWinSide model.Team, expectReason model.ResultReason, config *model.Config) {
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			return names.get(target), nil
+		},
+		model.R_FINISH: func(tc TestClient) (string, error) {
+			assert.Equal(t, string(expectWinSide), tc.info["win_side"])
+			assert.Equal(t, string(expectReason), tc.info["result_reason"])
+			return "", nil
+		},
+	}
+	executeGame(t, players, config, handlers)
+}
@@ -0,0 +1,49 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleDefinition1(t *testing.T) {
+	t.Log("役職定義: 設定ファイルで定義した狂信者は人狼を知り、人狼は狂信者を知らない")
+	config, err := model.LoadFromPath("./config/fanatic.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			roleMap := tc.info["role_map"].(map[string]any)
+			switch tc.originalName {
+			case "FANATIC":
+				assert.Equal(t, 2, len(roleMap))
+				assert.Contains(t, roleMap, tc.gameName)
+				assert.Equal(t, "FANATIC", roleMap[tc.gameName])
+				roles := make([]any, 0)
+				for _, role := range roleMap {
+					roles = append(roles, role)
+				}
+				assert.ElementsMatch(t, []any{"FANATIC", model.R_WEREWOLF.String()}, roles)
+			default:
+				assert.Equal(t, 1, len(roleMap))
+			}
+			return "", nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "FANATIC", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+}
+
+func TestRoleDefinition2(t *testing.T) {
+	t.Log("役職定義: 不明な能力を持つ役職定義はエラーになる")
+	config, err := model.LoadFromPath("./config/fanatic.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Logic.RoleDefinitions[0].Abilities = []string{"curse"}
+
+	_, err = model.NewSetting(*config)
+	assert.Error(t, err)
+}
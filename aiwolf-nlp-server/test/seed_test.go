@@ -0,0 +1,97 @@
+package test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type seedResult struct {
+	seats     map[string]string
+	roles     map[string]string
+	talkOrder []string
+}
+
+func TestSeed1(t *testing.T) {
+	t.Log("シード値: 同じシード値と同じ応答であれば、席順、役職、発言順が同じになる")
+	results := make([]*seedResult, 2)
+	t.Run("games", func(t *testing.T) {
+		for i := range results {
+			results[i] = &seedResult{
+				seats: make(map[string]string),
+				roles: make(map[string]string),
+			}
+			t.Run("game", func(t *testing.T) {
+				config, err := model.LoadFromPath("./config/talk.yml")
+				if err != nil {
+					t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+				}
+				config.Matching.IsOptimize = false
+				seed := int64(42)
+				config.Game.Seed = &seed
+
+				executeSeed(t, results[i], config)
+			})
+		}
+	})
+	assert.Len(t, results[0].seats, 5)
+	assert.NotEmpty(t, results[0].talkOrder)
+	assert.Equal(t, results[0].seats, results[1].seats)
+	assert.Equal(t, results[0].roles, results[1].roles)
+	assert.Equal(t, results[0].talkOrder, results[1].talkOrder)
+}
+
+func TestSeed2(t *testing.T) {
+	t.Log("シード値: シード値が異なれば、エージェントに割り当てられる役職が変わる")
+	seeds := []int64{1, 2, 3, 4}
+	results := make([]*seedResult, len(seeds))
+	t.Run("games", func(t *testing.T) {
+		for i, seed := range seeds {
+			results[i] = &seedResult{
+				seats: make(map[string]string),
+				roles: make(map[string]string),
+			}
+			t.Run("game", func(t *testing.T) {
+				config, err := model.LoadFromPath("./config/talk.yml")
+				if err != nil {
+					t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+				}
+				config.Matching.IsOptimize = false
+				config.Game.Seed = &seed
+
+				executeSeed(t, results[i], config)
+			})
+		}
+	})
+	varied := false
+	for _, result := range results[1:] {
+		assert.Len(t, result.roles, 5)
+		if !assert.ObjectsAreEqual(results[0].roles, result.roles) {
+			varied = true
+		}
+	}
+	assert.True(t, varied, "シード値によらず役職の割り当てが同じです")
+}
+
+func executeSeed(t *testing.T, result *seedResult, config *model.Config) {
+	var mu sync.Mutex
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			result.seats[tc.originalName] = tc.gameName
+			result.roles[tc.originalName] = tc.role.Name
+			return "", nil
+		},
+		model.R_TALK: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			result.talkOrder = append(result.talkOrder, tc.originalName)
+			return "テスト", nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpectator1(t *testing.T) {
+	t.Log("観戦モード: 死亡したエージェントはトークの配信と全員の役職を受け取り、墓場チャットを行う")
+	config, err := model.LoadFromPath("./config/spectator.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+
+	var mu sync.Mutex
+	broadcastCounts := make(map[string]int)
+	graveyardTalks := 0
+	talked := make(map[string]bool)
+
+	handlers := spectatorHandlers(t, &mu, talked, func(tc TestClient, roleMap map[string]any) {
+		if int(tc.info["day"].(float64)) == 1 && isSpectatorTarget(tc.originalName) {
+			assert.Equal(t, 5, len(roleMap))
+		} else {
+			assert.Equal(t, 1, len(roleMap))
+		}
+	})
+	handlers[model.R_TALK_BROADCAST] = func(tc TestClient) (string, error) {
+		assert.True(t, isSpectatorTarget(tc.originalName))
+		mu.Lock()
+		broadcastCounts[tc.originalName]++
+		mu.Unlock()
+		return "", nil
+	}
+	handlers[model.R_GRAVEYARD_TALK] = func(tc TestClient) (string, error) {
+		assert.True(t, isSpectatorTarget(tc.originalName))
+		mu.Lock()
+		defer mu.Unlock()
+		key := "graveyard-" + tc.originalName
+		if talked[key] {
+			return model.T_OVER, nil
+		}
+		talked[key] = true
+		graveyardTalks++
+		return "安らかに", nil
+	}
+	handlers[model.R_FINISH] = func(tc TestClient) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		keys := make([]string, 0)
+		dayOneTalks := 0
+		for _, talk := range tc.talkHistory {
+			talk := talk.(map[string]any)
+			keys = append(keys, fmt.Sprintf("%v-%v", talk["day"], talk["idx"]))
+			if talk["day"] == float64(1) {
+				dayOneTalks++
+			}
+		}
+		slices.Sort(keys)
+		assert.Equal(t, len(keys), len(slices.Compact(keys)))
+		if isSpectatorTarget(tc.originalName) {
+			assert.Greater(t, broadcastCounts[tc.originalName], 0)
+			assert.Equal(t, dayOneTalks, broadcastCounts[tc.originalName])
+			graveyardTexts := 0
+			for _, talk := range tc.graveyardTalkHistory {
+				if talk.(map[string]any)["text"] != model.T_OVER {
+					graveyardTexts++
+				}
+			}
+			assert.Equal(t, 2, graveyardTalks)
+			assert.Equal(t, graveyardTalks, graveyardTexts)
+		} else {
+			assert.Equal(t, 0, broadcastCounts[tc.originalName])
+			assert.Equal(t, 0, len(tc.graveyardTalkHistory))
+		}
+		return "", nil
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+}
+
+func TestSpectator2(t *testing.T) {
+	t.Log("観戦モード: 観戦モードが無効な場合、死亡したエージェントはトークの配信や役職を受け取らない")
+	config, err := model.LoadFromPath("./config/spectator.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Spectator = model.SpectatorConfig{}
+
+	var mu sync.Mutex
+	talked := make(map[string]bool)
+
+	handlers := spectatorHandlers(t, &mu, talked, func(tc TestClient, roleMap map[string]any) {
+		assert.Equal(t, 1, len(roleMap))
+	})
+	handlers[model.R_TALK_BROADCAST] = func(tc TestClient) (string, error) {
+		t.Errorf("観戦モードが無効であるにもかかわらず、トークの配信を受信しました: %s", tc.originalName)
+		return "", nil
+	}
+	handlers[model.R_GRAVEYARD_TALK] = func(tc TestClient) (string, error) {
+		t.Errorf("観戦モードが無効であるにもかかわらず、墓場チャットのリクエストを受信しました: %s", tc.originalName)
+		return model.T_OVER, nil
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+}
+
+func TestSpectator3(t *testing.T) {
+	t.Log("観戦モード: 観戦モードを有効にせずに墓場チャットを設定した場合はエラーになる")
+	config, err := model.LoadFromPath("./config/spectator.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Spectator.Enable = false
+
+	_, err = model.NewSetting(*config)
+	assert.Error(t, err)
+}
+
+func isSpectatorTarget(name string) bool {
+	return name == "VILLAGER-A" || name == "VILLAGER-B"
+}
+
+func spectatorHandlers(t *testing.T, mu *sync.Mutex, talked map[string]bool, validateRoleMap func(tc TestClient, roleMap map[string]any)) map[model.Request]func(tc TestClient) (string, error) {
+	names := newNameRegistry([]string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"})
+	return map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_DAILY_INITIALIZE: func(tc TestClient) (string, error) {
+			validateRoleMap(tc, tc.info["role_map"].(map[string]any))
+			return "", nil
+		},
+		model.R_TALK: func(tc TestClient) (string, error) {
+			assert.False(t, int(tc.info["day"].(float64)) == 1 && isSpectatorTarget(tc.originalName))
+			mu.Lock()
+			defer mu.Unlock()
+			key := fmt.Sprintf("%v-%s", tc.info["day"], tc.originalName)
+			if talked[key] {
+				return model.T_OVER, nil
+			}
+			talked[key] = true
+			return "こんにちは", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			return names.get("VILLAGER-B"), nil
+		},
+		model.R_ATTACK: func(tc TestClient) (string, error) {
+			return names.get("VILLAGER-A"), nil
+		},
+	}
+}
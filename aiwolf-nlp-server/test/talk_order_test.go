@@ -0,0 +1,245 @@
+package test
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTalkOrder1(t *testing.T) {
+	t.Log("発言順: 席順の場合、毎ターンエージェントのインデックス順に発言する")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Talk.Order = "seat"
+
+	executeTalkOrder(t, config, nil, func(turns [][]string, seats []string) {
+		for _, agents := range turns {
+			assert.Equal(t, seats, agents)
+		}
+	})
+}
+
+func TestTalkOrder2(t *testing.T) {
+	t.Log("発言順: ローテーションの場合、ターンごとに最初の発言者が1つずつずれる")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Talk.Order = "rotate"
+
+	executeTalkOrder(t, config, nil, func(turns [][]string, seats []string) {
+		for i, agents := range turns {
+			expected := append(slices.Clone(seats[i%len(seats):]), seats[:i%len(seats)]...)
+			assert.Equal(t, expected, agents)
+		}
+	})
+}
+
+func TestTalkOrder3(t *testing.T) {
+	t.Log("発言順: メンション順の場合、メンションされたエージェントが次に発言する")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Talk.Order = "mention"
+
+	var target string
+	message := func(tc TestClient, names *nameRegistry, turn int) string {
+		target = names.get("VILLAGER-B")
+		if turn == 0 && tc.originalName != "VILLAGER-B" {
+			return fmt.Sprintf("@%s どう思う?", target)
+		}
+		return "Hello World!"
+	}
+	executeTalkOrder(t, config, message, func(turns [][]string, seats []string) {
+		assert.LessOrEqual(t, slices.Index(turns[0], target), 1)
+		assert.Equal(t, target, turns[1][0])
+	})
+}
+
+func TestTalkOrder4(t *testing.T) {
+	t.Log("発言順: 不明な発言順を指定した場合はエラーになる")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Talk.Order = "random"
+
+	_, err = model.NewSetting(*config)
+	assert.Error(t, err)
+}
+
+func executeTalkOrder(t *testing.T, config *model.Config, message func(tc TestClient, names *nameRegistry, turn int) string, validate func(turns [][]string, seats []string)) {
+	// 席順とゲーム内の名前の順序を一致させるため、プロフィールを使用しない
+	config.CustomProfile.Enable = false
+
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+	messageIdxMap := make(map[string]int)
+	var mu sync.Mutex
+	var turns [][]string
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_TALK: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			messageIdx := messageIdxMap[tc.originalName]
+			messageIdxMap[tc.originalName]++
+			if messageIdx >= 2 {
+				return model.T_OVER, nil
+			}
+			if message != nil {
+				return message(tc, names, messageIdx), nil
+			}
+			return "Hello World!", nil
+		},
+		model.R_DAILY_FINISH: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if turns != nil {
+				return "", nil
+			}
+			for _, talk := range tc.talkHistory {
+				talk := talk.(map[string]any)
+				turn := int(talk["turn"].(float64))
+				for len(turns) <= turn {
+					turns = append(turns, []string{})
+				}
+				assert.Equal(t, len(turns[turn]), int(talk["position"].(float64)))
+				turns[turn] = append(turns[turn], talk["agent"].(string))
+			}
+			return "", nil
+		},
+	}
+	executeGame(t, players, config, handlers)
+
+	nameMap := names.snapshot()
+	seats := make([]string, 0, len(nameMap))
+	for _, name := range nameMap {
+		seats = append(seats, name)
+	}
+	slices.Sort(seats)
+	assert.Equal(t, 3, len(turns))
+	validate(turns, seats)
+}
+
+func TestTalkBid1(t *testing.T) {
+	t.Log("入札制: 最も高い入札をしたエージェントが発言権を得る")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Talk.Order = "bid"
+
+	executeTalkBid(t, config, func(speakers []string, nameMap map[string]string) {
+		assert.Equal(t, 15, len(speakers))
+		for i := range 3 {
+			assert.Equal(t, nameMap["SEER"], speakers[i])
+		}
+	})
+}
+
+func TestTalkBid2(t *testing.T) {
+	t.Log("入札制: 連続して発言権を得られる回数が制限されている場合、他のエージェントに発言権が移る")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Talk.Order = "bid"
+	config.Game.Talk.Bid.MaxConsecutive = 1
+
+	executeTalkBid(t, config, func(speakers []string, nameMap map[string]string) {
+		assert.Equal(t, 15, len(speakers))
+		for i := range 3 {
+			assert.Equal(t, nameMap["SEER"], speakers[i*2])
+			assert.NotEqual(t, nameMap["SEER"], speakers[i*2+1])
+		}
+	})
+}
+
+func TestTalkBid3(t *testing.T) {
+	t.Log("入札制: 連続して発言権を得られる回数が負の場合はエラーになる")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Talk.Order = "bid"
+	config.Game.Talk.Bid.MaxConsecutive = -1
+
+	_, err = model.NewSetting(*config)
+	assert.Error(t, err)
+}
+
+func TestTalkBid4(t *testing.T) {
+	t.Log("入札制: 発言可能なエージェントがいる限り、1日の発言回数の上限×エージェント数まで発言権が与えられる")
+	config, err := model.LoadFromPath("./config/talk.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Game.Talk.Order = "bid"
+	config.Game.Talk.MaxCount.PerAgent = 4
+	config.Game.Talk.MaxCount.PerDay = 2
+
+	var mu sync.Mutex
+	var speakers []string
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_BID: func(tc TestClient) (string, error) {
+			return "1", nil
+		},
+		model.R_TALK: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if day, ok := tc.info["day"].(float64); ok && day == 0 {
+				speakers = append(speakers, tc.gameName)
+			}
+			return "Hello World!", nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+	assert.Equal(t, 10, len(speakers))
+}
+
+func executeTalkBid(t *testing.T, config *model.Config, validate func(speakers []string, nameMap map[string]string)) {
+	nameMap := make(map[string]string)
+	messageIdxMap := make(map[string]int)
+	var mu sync.Mutex
+	var speakers []string
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			nameMap[tc.originalName] = tc.gameName
+			return "", nil
+		},
+		model.R_BID: func(tc TestClient) (string, error) {
+			if tc.originalName == "SEER" {
+				return "10", nil
+			}
+			return "1", nil
+		},
+		model.R_TALK: func(tc TestClient) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			speakers = append(speakers, tc.gameName)
+			messageIdx := messageIdxMap[tc.originalName]
+			messageIdxMap[tc.originalName]++
+			if messageIdx >= 2 {
+				return model.T_OVER, nil
+			}
+			return "Hello World!", nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+	validate(speakers, nameMap)
+}
@@ -32,6 +32,7 @@ func executeTalkPhase(t *testing.T, sendMessagesMap map[string][]string, config
 	nameMap := make(map[string]string)
 
 	messageIdxMap := make(map[string]int)
+	positionMap := make(map[int]int)
 
 	var idx float64 = 0
 	expectTalks := []any{}
@@ -59,14 +60,18 @@ func executeTalkPhase(t *testing.T, sendMessagesMap map[string][]string, config
 			}
 			tc.t.Logf("トーク: %s < %s", tc.gameName, message)
 
+			position := positionMap[messageIdx]
+			positionMap[messageIdx]++
+
 			expectTalks = append(expectTalks, map[string]any{
-				"idx":   idx,
-				"day":   tc.info["day"].(float64),
-				"turn":  float64(messageIdx),
-				"agent": tc.gameName,
-				"text":  message,
-				"skip":  message == model.T_SKIP || message == model.T_FORCE_SKIP,
-				"over":  message == model.T_OVER,
+				"idx":      idx,
+				"day":      tc.info["day"].(float64),
+				"turn":     float64(messageIdx),
+				"position": float64(position),
+				"agent":    tc.gameName,
+				"text":     message,
+				"skip":     message == model.T_SKIP || message == model.T_FORCE_SKIP,
+				"over":     message == model.T_OVER,
 			})
 			idx++
 			return message, nil
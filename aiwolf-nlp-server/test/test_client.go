@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,18 +16,78 @@ import (
 )
 
 type TestClient struct {
-	t              *testing.T
-	conn           *websocket.Conn
-	done           chan struct{}
-	originalName   string
-	gameName       string
-	request        model.Request
-	info           map[string]any
-	setting        map[string]any
-	talkHistory    []any
-	whisperHistory []any
-	role           model.Role
-	handlers       map[model.Request]func(tc TestClient) (string, error)
+	t                    *testing.T
+	conn                 *websocket.Conn
+	done                 chan struct{}
+	originalName         string
+	gameName             string
+	request              model.Request
+	info                 map[string]any
+	setting              map[string]any
+	talkHistory          []any
+	whisperHistory       []any
+	masonTalkHistory     []any
+	graveyardTalkHistory []any
+	promptReason         string
+	deadline             time.Time
+	remainingTime        int
+	requestID            string
+	hello                map[string]any
+	role                 model.Role
+	handlers             map[model.Request]func(tc TestClient) (string, error)
+}
+
+// 参加者の名前が登録されるまで待つ最大時間
+const nameRegistryTimeout = 10 * time.Second
+
+// nameRegistry は元の名前からゲーム内の名前を引くための対応表です
+// INITIALIZE はレスポンスを待たずに全員へ送信されるため、他のクライアントのINITIALIZEが処理される前に参照される場合があります
+// そのため、参加者の名前は登録されるまで待ってから返します
+type nameRegistry struct {
+	mu           sync.Mutex
+	participants []string
+	names        map[string]string
+}
+
+func newNameRegistry(participants []string) *nameRegistry {
+	return &nameRegistry{
+		participants: participants,
+		names:        make(map[string]string),
+	}
+}
+
+func (r *nameRegistry) register(tc TestClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.names[tc.originalName] = tc.gameName
+}
+
+// lookup は元の名前に対応するゲーム内の名前を返します
+// 参加者でない名前の場合は、待たずに見つからなかったことを返します
+func (r *nameRegistry) lookup(originalName string) (string, bool) {
+	deadline := time.Now().Add(nameRegistryTimeout)
+	for {
+		r.mu.Lock()
+		gameName, exists := r.names[originalName]
+		r.mu.Unlock()
+		if exists || !slices.Contains(r.participants, originalName) || time.Now().After(deadline) {
+			return gameName, exists
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// get は元の名前に対応するゲーム内の名前を返し、見つからない場合は空文字列を返します
+func (r *nameRegistry) get(originalName string) string {
+	gameName, _ := r.lookup(originalName)
+	return gameName
+}
+
+// snapshot は現在の対応表のコピーを返します
+func (r *nameRegistry) snapshot() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.names)
 }
 
 func NewTestClient(t *testing.T, u url.URL, name string, handlers map[model.Request]func(tc TestClient) (string, error)) (*TestClient, error) {
@@ -64,6 +127,9 @@ func (tc *TestClient) listen() {
 		}
 
 		req := model.RequestFromString(recv["request"].(string))
+		if requestID, exists := recv["request_id"].(string); exists {
+			tc.requestID = requestID
+		}
 		resp, err := tc.handleRequest(req, recv)
 		if err != nil {
 			tc.t.Error(err)
@@ -128,7 +194,16 @@ func (tc *TestClient) setSetting(recv map[string]any) error {
 func (tc *TestClient) handleRequest(request model.Request, recv map[string]any) (string, error) {
 	switch request {
 	case model.R_NAME:
+		if handler, exists := tc.handlers[model.R_NAME]; exists {
+			return handler(*tc)
+		}
 		return tc.originalName, nil
+	case model.R_HELLO:
+		if hello, exists := recv["hello"].(map[string]any); exists {
+			tc.hello = hello
+		} else {
+			return "", errors.New("helloが見つかりません")
+		}
 	case model.R_INITIALIZE, model.R_DAILY_INITIALIZE:
 		err := tc.setInfo(recv)
 		if err != nil {
@@ -138,12 +213,12 @@ func (tc *TestClient) handleRequest(request model.Request, recv map[string]any)
 		if err != nil {
 			return "", err
 		}
-	case model.R_VOTE, model.R_DIVINE, model.R_GUARD:
+	case model.R_VOTE, model.R_DIVINE, model.R_GUARD, model.R_LAST_WORDS, model.R_BID:
 		err := tc.setInfo(recv)
 		if err != nil {
 			return "", err
 		}
-	case model.R_DAILY_FINISH, model.R_TALK, model.R_WHISPER, model.R_ATTACK:
+	case model.R_DAILY_FINISH, model.R_TALK, model.R_WHISPER, model.R_MASON_TALK, model.R_GRAVEYARD_TALK, model.R_ATTACK:
 		err := tc.setInfo(recv)
 		if err != nil {
 			return "", err
@@ -162,11 +237,63 @@ func (tc *TestClient) handleRequest(request model.Request, recv map[string]any)
 				return "", errors.New("whisper_historyが見つかりません")
 			}
 		}
+		if request == model.R_MASON_TALK || (request == model.R_DAILY_FINISH && tc.role == model.R_MASON) {
+			if masonTalkHistory, exists := recv["mason_talk_history"].([]any); exists {
+				tc.masonTalkHistory = append(tc.masonTalkHistory, masonTalkHistory...)
+			} else {
+				return "", errors.New("mason_talk_historyが見つかりません")
+			}
+		}
+		if graveyardTalkHistory, exists := recv["graveyard_talk_history"].([]any); exists {
+			tc.graveyardTalkHistory = append(tc.graveyardTalkHistory, graveyardTalkHistory...)
+		} else if request == model.R_GRAVEYARD_TALK {
+			return "", errors.New("graveyard_talk_historyが見つかりません")
+		}
+	case model.R_TALK_PROMPT:
+		err := tc.setInfo(recv)
+		if err != nil {
+			return "", err
+		}
+		if promptReason, exists := recv["prompt_reason"].(string); exists {
+			tc.promptReason = promptReason
+		} else {
+			return "", errors.New("prompt_reasonが見つかりません")
+		}
+	case model.R_TALK_START, model.R_TALK_REMAINING_TIME:
+		err := tc.setInfo(recv)
+		if err != nil {
+			return "", err
+		}
+		if deadline, exists := recv["deadline"].(string); exists {
+			parsed, err := time.Parse(time.RFC3339Nano, deadline)
+			if err != nil {
+				return "", fmt.Errorf("deadlineの解析に失敗しました: %v", err)
+			}
+			tc.deadline = parsed
+		} else {
+			return "", errors.New("deadlineが見つかりません")
+		}
+		if request == model.R_TALK_REMAINING_TIME {
+			if remainingTime, exists := recv["remaining_time"].(float64); exists {
+				tc.remainingTime = int(remainingTime)
+			} else {
+				return "", errors.New("remaining_timeが見つかりません")
+			}
+		}
+	case model.R_TALK_BROADCAST:
+		if talkHistory, exists := recv["talk_history"].([]any); exists {
+			tc.talkHistory = append(tc.talkHistory, talkHistory...)
+		} else {
+			return "", errors.New("talk_historyが見つかりません")
+		}
 	case model.R_FINISH:
 		err := tc.setInfo(recv)
 		if err != nil {
 			return "", err
 		}
+		if talkHistory, exists := recv["talk_history"].([]any); exists {
+			tc.talkHistory = append(tc.talkHistory, talkHistory...)
+		}
 	}
 	if handler, exists := tc.handlers[request]; exists {
 		resp, err := handler(*tc)
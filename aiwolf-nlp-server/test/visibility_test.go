@@ -0,0 +1,104 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/aiwolfdial/aiwolf-nlp-server/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVisibility1(t *testing.T) {
+	t.Log("可視性: 役職一覧の設定により、狂人が人狼を知ることができる")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Logic.Visibility.RoleMap = map[string][]string{
+		model.R_WEREWOLF.Name: {model.R_POSSESSED.Name},
+	}
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			roleMap := tc.info["role_map"].(map[string]any)
+			if tc.originalName != "POSSESSED" {
+				assert.Equal(t, 1, len(roleMap))
+				return "", nil
+			}
+			roles := make([]any, 0)
+			for _, role := range roleMap {
+				roles = append(roles, role)
+			}
+			assert.ElementsMatch(t, []any{model.R_POSSESSED.String(), model.R_WEREWOLF.String()}, roles)
+			return "", nil
+		},
+	}
+	executeGame(t, []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}, config, handlers)
+}
+
+func TestVisibility2(t *testing.T) {
+	t.Log("可視性: 追放された役職の公開が有効な場合、翌日に全エージェントが追放された役職を知る")
+	config, err := model.LoadFromPath("./config/phase.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Logic.Visibility.ExecutedRole = &[]string{"ALL"}
+
+	executeVisibility(t, config, func(tc TestClient, nameMap map[string]string) {
+		roleMap := tc.info["role_map"].(map[string]any)
+		if int(tc.info["day"].(float64)) == 0 || tc.originalName == "VILLAGER-B" {
+			assert.Equal(t, 1, len(roleMap))
+			return
+		}
+		assert.Equal(t, 2, len(roleMap))
+		assert.Equal(t, model.R_VILLAGER.String(), roleMap[nameMap["VILLAGER-B"]])
+	})
+}
+
+func TestVisibility3(t *testing.T) {
+	t.Log("可視性: 投票結果を参照できる役職を指定した場合、その役職のみが投票結果を受け取る")
+	config, err := model.LoadFromPath("./config/phase.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Logic.Visibility.VoteList = &[]string{model.R_SEER.Name}
+
+	executeVisibility(t, config, func(tc TestClient, nameMap map[string]string) {
+		if int(tc.info["day"].(float64)) == 1 && tc.originalName == "SEER" {
+			assert.Contains(t, tc.info, "vote_list")
+		} else {
+			assert.NotContains(t, tc.info, "vote_list")
+		}
+	})
+}
+
+func TestVisibility4(t *testing.T) {
+	t.Log("可視性: 不明な役職名を指定した場合はエラーになる")
+	config, err := model.LoadFromPath("./config/execution.yml")
+	if err != nil {
+		t.Fatalf("設定ファイルの読み込みに失敗しました: %v", err)
+	}
+	config.Logic.Visibility.Whisper = &[]string{"WITCH"}
+
+	_, err = model.NewSetting(*config)
+	assert.Error(t, err)
+}
+
+func executeVisibility(t *testing.T, config *model.Config, validate func(tc TestClient, nameMap map[string]string)) {
+	players := []string{"WEREWOLF", "POSSESSED", "SEER", "VILLAGER-A", "VILLAGER-B"}
+	names := newNameRegistry(players)
+
+	handlers := map[model.Request]func(tc TestClient) (string, error){
+		model.R_INITIALIZE: func(tc TestClient) (string, error) {
+			names.register(tc)
+			return "", nil
+		},
+		model.R_DAILY_INITIALIZE: func(tc TestClient) (string, error) {
+			validate(tc, names.snapshot())
+			return "", nil
+		},
+		model.R_VOTE: func(tc TestClient) (string, error) {
+			return names.get("VILLAGER-B"), nil
+		},
+	}
+	executeGame(t, players, config, handlers)
+}
@@ -1,13 +1,13 @@
 package util
 
 import (
-	"math/rand"
+	"math/rand/v2"
 
 	"github.com/aiwolfdial/aiwolf-nlp-server/model"
 )
 
-func SelectRandomAgent(agents []model.Agent) model.Agent {
-	return agents[rand.Intn(len(agents))]
+func SelectRandomAgent(rng *rand.Rand, agents []model.Agent) model.Agent {
+	return agents[rng.IntN(len(agents))]
 }
 
 func FilterAgents(agents []*model.Agent, filter func(*model.Agent) bool) []*model.Agent {
@@ -3,6 +3,7 @@ package util
 import (
 	"maps"
 	"math/rand/v2"
+	"slices"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -25,21 +26,61 @@ func CountAliveTeams(statusMap map[model.Agent]model.Status) (int, int) {
 	return humans, werewolfs
 }
 
-func CalcWinSideTeam(statusMap map[model.Agent]model.Status) model.Team {
+func CountAliveRoleTeam(statusMap map[model.Agent]model.Status, team model.Team) int {
+	var count int
+	for agent, status := range statusMap {
+		if status == model.S_ALIVE && agent.Role.Team == team {
+			count++
+		}
+	}
+	return count
+}
+
+func CalcWinSideTeam(statusMap map[model.Agent]model.Status) (model.Team, model.ResultReason) {
 	humans, werewolfs := CountAliveTeams(statusMap)
+	winSide := model.T_NONE
+	var reason model.ResultReason
 	if humans <= werewolfs {
-		return model.T_WEREWOLF
+		winSide = model.T_WEREWOLF
+		reason = model.RR_PARITY
+	} else if werewolfs == 0 {
+		winSide = model.T_VILLAGER
+		reason = model.RR_ELIMINATION
 	}
-	if werewolfs == 0 {
-		return model.T_VILLAGER
+	return applyFoxWin(statusMap, winSide), reason
+}
+
+func CalcMaxDayWinSideTeam(statusMap map[model.Agent]model.Status, outcome model.MaxDayOutcome) model.Team {
+	winSide := model.T_NONE
+	switch outcome {
+	case model.MO_WEREWOLF:
+		winSide = model.T_WEREWOLF
+	case model.MO_VILLAGER:
+		winSide = model.T_VILLAGER
+	case model.MO_SURVIVORS:
+		villagers := CountAliveRoleTeam(statusMap, model.T_VILLAGER)
+		werewolves := CountAliveRoleTeam(statusMap, model.T_WEREWOLF)
+		if villagers > werewolves {
+			winSide = model.T_VILLAGER
+		} else if werewolves > villagers {
+			winSide = model.T_WEREWOLF
+		}
 	}
-	return model.T_NONE
+	return applyFoxWin(statusMap, winSide)
+}
+
+// 妖狐が生存している場合は、いずれの陣営の勝利条件を満たしても妖狐の勝利とする
+func applyFoxWin(statusMap map[model.Agent]model.Status, winSide model.Team) model.Team {
+	if winSide != model.T_NONE && CountAliveRoleTeam(statusMap, model.T_FOX) > 0 {
+		return model.T_FOX
+	}
+	return winSide
 }
 
 func CalcHasErrorAgents(agents []*model.Agent) int {
 	var count int
 	for _, a := range agents {
-		if a.HasError {
+		if a.HasError() {
 			count++
 		}
 	}
@@ -54,73 +95,98 @@ func GetRoleMap(agents []*model.Agent) map[model.Agent]model.Role {
 	return roleMap
 }
 
-func CreateAgents(conns []model.Connection, roles map[model.Role]int) []*model.Agent {
-	rolesCopy := make(map[model.Role]int)
-	maps.Copy(rolesCopy, roles)
+func CreateAgents(rng *rand.Rand, conns []model.Connection, roles map[model.Role]int) []*model.Agent {
+	shuffledRoles := shuffleRoles(rng, roles)
 	agents := make([]*model.Agent, 0)
 	for i, conn := range conns {
-		role := assignRole(rolesCopy)
-		agent := model.NewAgent(i+1, role, conn)
+		agent := model.NewAgent(i+1, assignRole(shuffledRoles, i), conn)
 		agents = append(agents, agent)
 	}
 	return agents
 }
 
-func CreateAgentsWithProfiles(conns []model.Connection, roles map[model.Role]int, profiles []model.Profile, encoding map[string]string) []*model.Agent {
-	rolesCopy := make(map[model.Role]int)
-	maps.Copy(rolesCopy, roles)
+func CreateAgentsWithProfiles(rng *rand.Rand, conns []model.Connection, roles map[model.Role]int, profiles []model.Profile, encoding map[string]string) []*model.Agent {
+	shuffledRoles := shuffleRoles(rng, roles)
 	agents := make([]*model.Agent, 0)
 
-	rand.Shuffle(len(profiles), func(i, j int) { profiles[i], profiles[j] = profiles[j], profiles[i] })
+	profiles = slices.Clone(profiles)
+	rng.Shuffle(len(profiles), func(i, j int) { profiles[i], profiles[j] = profiles[j], profiles[i] })
 
 	for i, conn := range conns {
-		role := assignRole(rolesCopy)
-		agent := model.NewAgentWithProfile(i+1, role, conn, profiles[i], encoding)
+		agent := model.NewAgentWithProfile(i+1, assignRole(shuffledRoles, i), conn, profiles[i], encoding)
 		agents = append(agents, agent)
 	}
 	return agents
 }
 
-func CreateAgentsWithRole(roleMapConns map[model.Role][]model.Connection) []*model.Agent {
+func CreateAgentsWithRole(rng *rand.Rand, roleMapConns map[model.Role][]model.Connection) []*model.Agent {
 	agents := make([]*model.Agent, 0)
-	i := 0
-	for role, conns := range roleMapConns {
-		for _, conn := range conns {
-			agent := model.NewAgent(i+1, role, conn)
-			i++
-			agents = append(agents, agent)
-		}
+	for i, seat := range shuffleSeats(rng, roleMapConns) {
+		agent := model.NewAgent(i+1, seat.role, seat.conn)
+		agents = append(agents, agent)
 	}
 	return agents
 }
 
-func CreateAgentsWithRoleAndProfile(roleMapConns map[model.Role][]model.Connection, profiles []model.Profile, encoding map[string]string) []*model.Agent {
+func CreateAgentsWithRoleAndProfile(rng *rand.Rand, roleMapConns map[model.Role][]model.Connection, profiles []model.Profile, encoding map[string]string) []*model.Agent {
 	agents := make([]*model.Agent, 0)
+	seats := shuffleSeats(rng, roleMapConns)
 
-	rand.Shuffle(len(profiles), func(i, j int) { profiles[i], profiles[j] = profiles[j], profiles[i] })
+	profiles = slices.Clone(profiles)
+	rng.Shuffle(len(profiles), func(i, j int) { profiles[i], profiles[j] = profiles[j], profiles[i] })
 
-	i := 0
-	for role, conns := range roleMapConns {
-		for _, conn := range conns {
-			profile := profiles[i]
-			agent := model.NewAgentWithProfile(i+1, role, conn, profile, encoding)
-			i++
-			agents = append(agents, agent)
-		}
+	for i, seat := range seats {
+		agent := model.NewAgentWithProfile(i+1, seat.role, seat.conn, profiles[i], encoding)
+		agents = append(agents, agent)
 	}
 	return agents
 }
 
-func assignRole(roles map[model.Role]int) model.Role {
-	for r, n := range roles {
-		if n > 0 {
-			roles[r]--
-			return r
+// 同じシード値から同じ結果を得るため、マップの反復順序に依存しないように役職名順に並べてからシャッフルする
+func sortedRoles[V any](roles map[model.Role]V) []model.Role {
+	return slices.SortedFunc(maps.Keys(roles), func(a, b model.Role) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+func shuffleRoles(rng *rand.Rand, roles map[model.Role]int) []model.Role {
+	shuffledRoles := make([]model.Role, 0)
+	for _, role := range sortedRoles(roles) {
+		for range roles[role] {
+			shuffledRoles = append(shuffledRoles, role)
 		}
 	}
+	rng.Shuffle(len(shuffledRoles), func(i, j int) {
+		shuffledRoles[i], shuffledRoles[j] = shuffledRoles[j], shuffledRoles[i]
+	})
+	return shuffledRoles
+}
+
+func assignRole(roles []model.Role, idx int) model.Role {
+	if idx < len(roles) {
+		return roles[idx]
+	}
 	return model.R_VILLAGER
 }
 
+type seat struct {
+	role model.Role
+	conn model.Connection
+}
+
+func shuffleSeats(rng *rand.Rand, roleMapConns map[model.Role][]model.Connection) []seat {
+	seats := make([]seat, 0)
+	for _, role := range sortedRoles(roleMapConns) {
+		for _, conn := range roleMapConns[role] {
+			seats = append(seats, seat{role: role, conn: conn})
+		}
+	}
+	rng.Shuffle(len(seats), func(i, j int) {
+		seats[i], seats[j] = seats[j], seats[i]
+	})
+	return seats
+}
+
 func GetCandidates(votes []model.Vote, condition func(model.Vote) bool) []model.Agent {
 	counter := make(map[model.Agent]int)
 	for _, vote := range votes {
@@ -144,6 +210,9 @@ func getMaxCountCandidates(counter map[model.Agent]int) []model.Agent {
 			candidates = append(candidates, agent)
 		}
 	}
+	slices.SortFunc(candidates, func(a, b model.Agent) int {
+		return a.Idx - b.Idx
+	})
 	return candidates
 }
 
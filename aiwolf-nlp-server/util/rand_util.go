@@ -0,0 +1,22 @@
+package util
+
+import "math/rand/v2"
+
+// 同じシード値から処理ごとに異なる乱数列を得るためのストリームID
+const (
+	STREAM_MATCHING uint64 = iota + 1
+	STREAM_GAME
+)
+
+func ResolveSeed(seed *int64) int64 {
+	if seed != nil {
+		return *seed
+	}
+	return rand.Int64()
+}
+
+// NewRand はシード値とストリームIDから乱数生成器を作成します
+// 同じシード値でもストリームIDが異なれば、異なる乱数列になります
+func NewRand(seed int64, stream uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(uint64(seed), stream))
+}
@@ -4,7 +4,9 @@ export enum Role {
     SEER = "SEER",
     BODYGUARD = "BODYGUARD",
     VILLAGER = "VILLAGER",
-    MEDIUM = "MEDIUM"
+    MEDIUM = "MEDIUM",
+    FOX = "FOX",
+    MASON = "MASON"
 }
 
 export enum Species {
@@ -20,6 +22,7 @@ export enum Status {
 export enum Teams {
     VILLAGER = "VILLAGER",
     WEREWOLF = "WEREWOLF",
+    FOX = "FOX",
 }
 
 export const RoleToSpecies: Record<Role, Species> = {
@@ -28,7 +31,9 @@ export const RoleToSpecies: Record<Role, Species> = {
     SEER: Species.HUMAN,
     BODYGUARD: Species.HUMAN,
     VILLAGER: Species.HUMAN,
-    MEDIUM: Species.HUMAN
+    MEDIUM: Species.HUMAN,
+    FOX: Species.HUMAN,
+    MASON: Species.HUMAN
 }
 
 export function IdxToName(idx: number | string): string {
@@ -12,11 +12,15 @@ export interface Talk {
     turnIdx: string;
     agentIdx: string;
     text: string;
+    position?: string;
+    order?: string;
+    lastWords?: boolean;
 }
 
 export interface Vote {
     agentIdx: string;
     targetIdx: string;
+    round?: string;
 }
 
 export interface Execution {
@@ -30,6 +34,10 @@ export interface Divine {
     result: Species;
 }
 
+export interface Curse {
+    targetIdx: string;
+}
+
 export interface Guard {
     agentIdx: string;
     targetIdx: string;
@@ -41,22 +49,32 @@ export interface Attack {
     result: boolean;
 }
 
+export interface SkippedPhase {
+    phase: string;
+    reason: string;
+}
+
 export interface Result {
     villagers: string;
     werewolves: string;
     winSide: Teams;
+    reason?: string;
 }
 
 export interface DayStatus {
     agents: Record<string, Agent>;
+    skippedPhases: SkippedPhase[];
     beforeWhisper: Talk[];
     talks: Talk[];
     votes: Vote[];
     execution: Execution | null;
     divine: Divine | null;
+    curse: Curse | null;
+    masonTalks: Talk[];
     afterWhisper: Talk[];
     guard: Guard | null;
     attackVotes: Vote[];
     attack: Attack | null;
+    graveyardTalks: Talk[];
     result: Result | null;
 }
\ No newline at end of file
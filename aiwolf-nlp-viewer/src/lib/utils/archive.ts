@@ -1,5 +1,7 @@
 import { IdxToName, Role, Species, Status, Teams } from '$lib/constants/common';
-import type { DayStatus } from '$lib/types/archive';
+import type { DayStatus, Talk } from '$lib/types/archive';
+
+const TALK_ORDERS = ["shuffle", "seat", "rotate", "reshuffle", "mention", "bid", "realtime"];
 
 
 export function processArchiveLog(data: string): Record<string, DayStatus> {
@@ -20,15 +22,19 @@ export function processArchiveLog(data: string): Record<string, DayStatus> {
 function initializeDayLog(): DayStatus {
     return {
         agents: {},
+        skippedPhases: [],
         beforeWhisper: [],
         talks: [],
         votes: [],
         execution: null,
         divine: null,
+        curse: null,
+        masonTalks: [],
         afterWhisper: [],
         guard: null,
         attackVotes: [],
         attack: null,
+        graveyardTalks: [],
         result: null,
     };
 }
@@ -51,11 +57,17 @@ function createLogHandlers(dayLog: DayStatus): Record<string, (data: string[]) =
                 gameName: gameName || IdxToName(idx)
             };
         },
-        talk: ([talkIdx, turn, agentIdx, text]) => {
-            dayLog.talks.push({ talkIdx, turnIdx: turn, agentIdx, text });
+        skipPhase: ([phase, ...reason]) => {
+            dayLog.skippedPhases.push({ phase, reason: reason.join(",") });
+        },
+        talk: (data) => {
+            dayLog.talks.push(parseTalk(data));
+        },
+        lastWords: ([talkIdx, turn, agentIdx, text]) => {
+            dayLog.talks.push({ talkIdx, turnIdx: turn, agentIdx, text, lastWords: true });
         },
-        vote: ([voteAgentIdx, targetAgentIdx]) => {
-            dayLog.votes.push({ agentIdx: voteAgentIdx, targetIdx: targetAgentIdx });
+        vote: ([voteAgentIdx, targetAgentIdx, round]) => {
+            dayLog.votes.push({ agentIdx: voteAgentIdx, targetIdx: targetAgentIdx, round });
         },
         execute: ([executedAgentIdx, executedRole]) => {
             dayLog.execution = {
@@ -70,9 +82,18 @@ function createLogHandlers(dayLog: DayStatus): Record<string, (data: string[]) =
                 result: Species[divineResult as keyof typeof Species],
             };
         },
-        whisper: ([talkIdx, turn, agentIdx, text]) => {
-            const whisperEntry = { talkIdx, turnIdx: turn, agentIdx, text };
-            if (dayLog.talks.length > 0) {
+        curse: ([targetIdx]) => {
+            dayLog.curse = { targetIdx };
+        },
+        masonTalk: (data) => {
+            dayLog.masonTalks.push(parseTalk(data));
+        },
+        graveyardTalk: (data) => {
+            dayLog.graveyardTalks.push(parseTalk(data));
+        },
+        whisper: (data) => {
+            const whisperEntry = parseTalk(data);
+            if (dayLog.talks.some((talk) => !talk.lastWords)) {
                 dayLog.afterWhisper.push(whisperEntry);
             } else {
                 dayLog.beforeWhisper.push(whisperEntry);
@@ -81,10 +102,11 @@ function createLogHandlers(dayLog: DayStatus): Record<string, (data: string[]) =
         guard: ([agentIdx, targetIdx, result]) => {
             dayLog.guard = { agentIdx, targetIdx, result };
         },
-        attackVote: ([attackVoteAgentIdx, attackTargetAgentIdx]) => {
+        attackVote: ([attackVoteAgentIdx, attackTargetAgentIdx, round]) => {
             dayLog.attackVotes.push({
                 agentIdx: attackVoteAgentIdx,
                 targetIdx: attackTargetAgentIdx,
+                round,
             });
         },
         attack: ([attackedAgentIdx, isSuccessful]) => {
@@ -93,15 +115,29 @@ function createLogHandlers(dayLog: DayStatus): Record<string, (data: string[]) =
                 result: isSuccessful === "true",
             };
         },
-        result: ([villagers, werewolves, winSide]) => {
+        result: ([villagers, werewolves, winSide, reason]) => {
             dayLog.result = {
                 villagers,
                 werewolves,
-                winSide: Teams[winSide as keyof typeof Teams]
+                winSide: Teams[winSide as keyof typeof Teams],
+                reason
             };
         },
     };
 }
+// 発言の末尾には発言の位置と発言順の種類が記録されている
+// 発言にカンマが含まれる場合があるため、末尾から取り出し、残りを発言として扱う
+// 発言の位置を記録していない古いログの場合は、全てを発言として扱う
+function parseTalk([talkIdx, turn, agentIdx, ...fields]: string[]): Talk {
+    let position: string | undefined;
+    let order: string | undefined;
+    if (fields.length >= 3 && TALK_ORDERS.includes(fields[fields.length - 1]) && /^\d+$/.test(fields[fields.length - 2])) {
+        order = fields.pop();
+        position = fields.pop();
+    }
+    return { talkIdx, turnIdx: turn, agentIdx, text: fields.join(","), position, order };
+}
+
 export function getColorFromName(name: string): string {
     const hash = calculateStringHash(name);
     const h = Math.abs(hash) % 360;